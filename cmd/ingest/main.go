@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spacesedan/sentiflow/config"
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
+	"github.com/spacesedan/sentiflow/internal/ingest"
+	"github.com/spacesedan/sentiflow/internal/logging"
+)
+
+func main() {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	config.LoadEnv(env)
+	logging.InitLogger()
+
+	keys, err := ingest.ParseAPIKeys(os.Getenv("INGEST_API_KEYS"))
+	if err != nil {
+		slog.Error("[Ingest] Failed to load API keys", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	for {
+		err := kafka_client.InitProducer(ctx)
+		if err == nil {
+			break
+		}
+
+		slog.Warn("Kafka init failed, retrying...", slog.String("error", err.Error()))
+		time.Sleep(5 * time.Second)
+	}
+	defer kafka_client.CloseProducer()
+
+	clients.InitValkey()
+	defer clients.CloseValkey()
+
+	addr := os.Getenv("INGEST_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           ingest.NewServer(keys).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("[Ingest] Shutting down ingestion server gracefully...")
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("[Ingest] Shutdown failed", slog.String("error", err.Error()))
+		}
+	}()
+
+	slog.Info("[Ingest] Listening for content", slog.String("addr", addr), slog.Int("keys", len(keys)))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("[Ingest] Server failed", slog.String("error", err.Error()))
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
 	"github.com/spacesedan/sentiflow/internal/logging"
 	"github.com/spacesedan/sentiflow/internal/producer"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
 )
 
 func main() {
@@ -48,19 +49,37 @@ func main() {
 	redditTicker := time.NewTicker(time.Duration(redditFetchInterval) * time.Second)
 	defer redditTicker.Stop()
 
-	producer.InitCategoryHelpers()
+	if err := taxonomy.Init(); err != nil {
+		slog.Error("Failed to load taxonomy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	go taxonomy.Watch(ctx)
+
+	producer.RegisterSource(producer.NewRedditSource())
+	if os.Getenv("HACKERNEWS_ENABLED") == "true" {
+		producer.RegisterSource(producer.NewHackerNewsSource())
+	}
+	if os.Getenv("RSS_FEEDS") != "" {
+		producer.RegisterSource(producer.NewRSSSource())
+	}
+	if os.Getenv("MASTODON_INSTANCES") != "" {
+		producer.RegisterSource(producer.NewMastodonSource())
+	}
+	if os.Getenv("BLUESKY_ENABLED") == "true" {
+		producer.RegisterSource(producer.NewBlueskySource())
+	}
 
 	// Handle graceful shutdown
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
 
-	producer.FetchRedditContentForTopics(ctx)
+	producer.FetchContentForTopics(ctx)
 
 	for {
 		select {
 
 		case <-redditTicker.C:
-			producer.FetchRedditContentForTopics(ctx)
+			producer.FetchContentForTopics(ctx)
 
 		case <-stopChan:
 			slog.Info("Shutting down producer gracefully...")
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spacesedan/sentiflow/config"
+	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
+	"github.com/spacesedan/sentiflow/internal/logging"
+	"github.com/spacesedan/sentiflow/internal/replay"
+)
+
+func main() {
+	format := flag.String("format", replay.FORMAT_AUTO, "record format: auto, raw or reddit")
+	rate := flag.Float64("rate", 20, "messages published per second, 0 disables throttling")
+	checkpointEvery := flag.Int("checkpoint-every", 100, "write the checkpoint every N published records")
+	timestampsNow := flag.Bool("timestamps-now", false, "replace every timestamp with the publish time")
+	timeShift := flag.Duration("time-shift", 0, "shift every timestamp by this duration")
+	dryRun := flag.Bool("dry-run", false, "validate the files without publishing")
+	flag.Parse()
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	config.LoadEnv(env)
+	logging.InitLogger()
+
+	if flag.NArg() == 0 {
+		slog.Error("[Replay] Usage: replay [flags] FILE.jsonl[.gz]...")
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if !*dryRun {
+		for {
+			err := kafka_client.InitProducer(ctx)
+			if err == nil {
+				break
+			}
+
+			slog.Warn("Kafka init failed, retrying...", slog.String("error", err.Error()))
+			time.Sleep(5 * time.Second)
+		}
+		defer kafka_client.CloseProducer()
+	}
+
+	for _, path := range flag.Args() {
+		// A dry run shouldn't move the checkpoint of a later real run
+		checkpointPath := path + ".checkpoint"
+		if *dryRun {
+			checkpointPath = ""
+		}
+
+		stats, err := replay.File(ctx, path, replay.Options{
+			Format:          *format,
+			Rate:            *rate,
+			CheckpointPath:  checkpointPath,
+			CheckpointEvery: *checkpointEvery,
+			TimestampsNow:   *timestampsNow,
+			TimeShift:       *timeShift,
+			DryRun:          *dryRun,
+		})
+
+		slog.Info("[Replay] File finished",
+			slog.String("file", path),
+			slog.Int("published", stats.Published),
+			slog.Int("invalid", stats.Invalid),
+			slog.Int("skipped", stats.Skipped))
+
+		if err != nil {
+			slog.Error("[Replay] Replay stopped", slog.String("error", err.Error()))
+			// os.Exit skips the deferred close, flush what was published first
+			if !*dryRun {
+				kafka_client.CloseProducer()
+			}
+			os.Exit(1)
+		}
+	}
+}
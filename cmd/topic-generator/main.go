@@ -8,7 +8,9 @@ import (
 
 	"github.com/spacesedan/sentiflow/config"
 	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
 	"github.com/spacesedan/sentiflow/internal/logging"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
 	topicgeneration "github.com/spacesedan/sentiflow/internal/topic_generation"
 )
 
@@ -23,12 +25,53 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
 	defer cancel()
 
-	headlines, err := clients.GetNewsAPIClient().GetTopHeadlinesByCategory()
+	if err := taxonomy.Init(); err != nil {
+		slog.Error("[TopicGenerator] Failed to load taxonomy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	llm, err := topicgeneration.NewTopicLLM()
+	if err != nil {
+		slog.Error("[TopicGenerator] Failed to configure the topic LLM", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	embedder, threshold, err := topicgeneration.NewTopicEmbedder()
+	if err != nil {
+		slog.Error("[TopicGenerator] Failed to configure topic embeddings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Articles are only published as content when NEWSAPI_PUBLISH_ARTICLES is set,
+	// otherwise headlines are used purely as seeds for topic generation
+	if os.Getenv("NEWSAPI_PUBLISH_ARTICLES") == "true" {
+		for {
+			err := kafka_client.InitProducer(ctx)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				slog.Error("[TopicGenerator] Gave up initializing Kafka producer", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+
+			slog.Warn("Kafka init failed, retrying...", slog.String("error", err.Error()))
+			time.Sleep(5 * time.Second)
+		}
+		defer kafka_client.CloseProducer()
+
+		clients.InitValkey()
+		defer clients.CloseValkey()
+
+		topicgeneration.PublishArticles = true
+	}
+
+	headlines, err := clients.GetNewsAPIClient().GetArticles()
 	if err != nil {
 		slog.Warn("[TopicGenerator] Failed to get Top headlines from the NewsAPI",
 			slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	topicgeneration.GenerateTopicsFromHeadlines(ctx, headlines)
+	topicgeneration.GenerateTopicsFromHeadlines(ctx, llm, embedder, threshold, headlines)
 	slog.Info("[TopicGenerator] Topic generation completed successfully")
 }
@@ -10,10 +10,11 @@ require (
 	github.com/confluentinc/confluent-kafka-go v1.9.2
 	github.com/jonreiter/govader v0.0.0-20230129030235-c72a790a959e
 	github.com/lmittmann/tint v1.0.7
-	github.com/openai/openai-go v0.1.0-alpha.56
 	github.com/russross/blackfriday/v2 v2.1.0
+	github.com/sashabaranov/go-openai v1.38.1
 	github.com/subosito/gotenv v1.6.0
 	github.com/valkey-io/valkey-go v1.0.55
+	golang.org/x/exp v0.0.0-20250218142911-aa4b98e5adaa
 	golang.org/x/oauth2 v0.26.0
 	google.golang.org/protobuf v1.36.5
 )
@@ -34,13 +35,7 @@ require (
 	github.com/aws/smithy-go v1.22.2 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
-	github.com/sashabaranov/go-openai v1.38.1 // indirect
 	github.com/stretchr/testify v1.10.0 // indirect
-	github.com/tidwall/gjson v1.18.0 // indirect
-	github.com/tidwall/match v1.1.1 // indirect
-	github.com/tidwall/pretty v1.2.1 // indirect
-	github.com/tidwall/sjson v1.2.5 // indirect
-	golang.org/x/exp v0.0.0-20250218142911-aa4b98e5adaa // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 	gonum.org/v1/gonum v0.15.1 // indirect
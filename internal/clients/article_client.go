@@ -0,0 +1,161 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/utils"
+)
+
+const (
+	// pages larger than this are cut off before extraction
+	ARTICLE_MAX_BODY_BYTES = 2 << 20
+	// extracted text is truncated to keep raw-content messages small
+	ARTICLE_MAX_TEXT = 20000
+	// redirects followed before giving up on a link
+	ARTICLE_MAX_REDIRECTS = 5
+)
+
+// ErrNotAnArticle is returned for links that do not point to an HTML page
+var ErrNotAnArticle = errors.New("[ArticleClient] Link is not an HTML page")
+
+// ErrBlockedAddress is returned for links that resolve to an address inside
+// our own network, links are user submitted and must never reach those
+var ErrBlockedAddress = errors.New("[ArticleClient] Link resolves to a blocked address")
+
+var (
+	articleClientInstance *ArticleClient
+	articleClientOnce     sync.Once
+)
+
+// ArticleClient downloads the pages link posts point to and extracts their main text
+type ArticleClient struct {
+	Client *http.Client
+}
+
+func GetArticleClient() *ArticleClient {
+	articleClientOnce.Do(func() {
+		dialer := &net.Dialer{
+			Timeout: 5 * time.Second,
+			// checked after DNS resolution so hostnames pointing inside the
+			// network are caught too
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				addr, err := netip.ParseAddr(host)
+				if err != nil || IsBlockedAddress(addr) {
+					return ErrBlockedAddress
+				}
+				return nil
+			},
+		}
+
+		articleClientInstance = &ArticleClient{
+			Client: &http.Client{
+				Timeout: 15 * time.Second,
+				Transport: &http.Transport{
+					// no proxy, a proxy would do the dialing and skip the check
+					Proxy:               nil,
+					DialContext:         dialer.DialContext,
+					TLSHandshakeTimeout: 5 * time.Second,
+					MaxIdleConns:        10,
+					IdleConnTimeout:     30 * time.Second,
+				},
+				CheckRedirect: func(req *http.Request, via []*http.Request) error {
+					if len(via) >= ARTICLE_MAX_REDIRECTS {
+						return fmt.Errorf("[ArticleClient] Stopped after %d redirects", len(via))
+					}
+					if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+						return fmt.Errorf("[ArticleClient] Redirect to unsupported scheme %q", req.URL.Scheme)
+					}
+					if IsBlockedHost(req.URL.Hostname()) {
+						return ErrBlockedAddress
+					}
+					return nil
+				},
+			},
+		}
+	})
+	return articleClientInstance
+}
+
+// FetchArticle downloads a page and returns its extracted title and text.
+// Articles are fetched once and are not retried, a failing site only costs us
+// the article and never the post itself.
+func (ac *ArticleClient) FetchArticle(ctx context.Context, articleURL string) (*models.LinkedArticle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, articleURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[ArticleClient] Failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", USER_AGENT)
+	req.Header.Set("Accept", "text/html, application/xhtml+xml")
+
+	resp, err := ac.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("[ArticleClient] Request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[ArticleClient] Unexpected status code: %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotAnArticle
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, ARTICLE_MAX_BODY_BYTES))
+	if err != nil {
+		return nil, fmt.Errorf("[ArticleClient] Failed to read page: %w", err)
+	}
+
+	title, text := utils.ExtractArticle(string(body))
+	if text == "" {
+		return nil, ErrNotAnArticle
+	}
+	if len(text) > ARTICLE_MAX_TEXT {
+		text = strings.ToValidUTF8(text[:ARTICLE_MAX_TEXT], "")
+	}
+
+	return &models.LinkedArticle{
+		URL:   resp.Request.URL.String(),
+		Title: title,
+		Text:  text,
+	}, nil
+}
+
+// IsBlockedAddress reports whether an address is loopback, private, link-local
+// (which includes cloud metadata endpoints) or unspecified
+func IsBlockedAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast()
+}
+
+// IsBlockedHost rejects hosts that are obviously internal before any request is
+// made, hostnames are resolved and checked again when dialing
+func IsBlockedHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return IsBlockedAddress(addr)
+	}
+	return false
+}
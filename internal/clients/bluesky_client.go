@@ -0,0 +1,114 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+const BLUESKY_API_URL = "https://api.bsky.app"
+
+var (
+	blueskyClientInstance *BlueskyClient
+	blueskyClientOnce     sync.Once
+)
+
+// BlueskyClient calls the AT Protocol XRPC endpoints of a Bluesky AppView,
+// BaseURL can be pointed at a local stub through BLUESKY_API_URL
+type BlueskyClient struct {
+	Client      *http.Client
+	BaseURL     string
+	AccessToken string
+}
+
+func GetBlueskyClient() *BlueskyClient {
+	blueskyClientOnce.Do(func() {
+		baseURL := os.Getenv("BLUESKY_API_URL")
+		if baseURL == "" {
+			baseURL = BLUESKY_API_URL
+		}
+
+		blueskyClientInstance = &BlueskyClient{
+			Client: &http.Client{
+				Timeout: 30 * time.Second,
+			},
+			BaseURL:     strings.TrimRight(baseURL, "/"),
+			AccessToken: os.Getenv("BLUESKY_ACCESS_TOKEN"),
+		}
+	})
+	return blueskyClientInstance
+}
+
+// SearchPosts calls app.bsky.feed.searchPosts for posts created after since,
+// newest first. An empty cursor in the response means there are no more pages.
+func (bc *BlueskyClient) SearchPosts(ctx context.Context, query, cursor string, since time.Time, limit int) (models.BlueskySearchPostsResponse, error) {
+	var response models.BlueskySearchPostsResponse
+
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("sort", "latest")
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("since", since.UTC().Format(time.RFC3339))
+	if cursor != "" {
+		params.Add("cursor", cursor)
+	}
+	endpoint := bc.BaseURL + "/xrpc/app.bsky.feed.searchPosts?" + params.Encode()
+
+	backoff := INITIAL_BACKOFF
+	for attempt := 1; attempt <= MAX_RETRIES; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return response, fmt.Errorf("[BlueskyClient] Failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", USER_AGENT)
+		req.Header.Set("Accept", "application/json")
+		if bc.AccessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+bc.AccessToken)
+		}
+
+		resp, err := bc.Client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return response, fmt.Errorf("[BlueskyClient] Failed to read response: %w", readErr)
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				return response, fmt.Errorf("[BlueskyClient] Failed to parse response: %w", err)
+			}
+			return response, nil
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return response, fmt.Errorf("[BlueskyClient] Unexpected status code %d", resp.StatusCode)
+			}
+		}
+
+		slog.Warn("[BlueskyClient] Search request failed, retrying...",
+			slog.String("query", query),
+			slog.Int("attempt", attempt),
+			slog.String("error", errMsg(err, resp)))
+
+		select {
+		case <-ctx.Done():
+			return response, ctx.Err()
+		case <-time.After(backoff):
+			backoff = minDuration(backoff*2, MAX_BACKOFF)
+		}
+	}
+
+	return response, fmt.Errorf("[BlueskyClient] Max retries reached for query %s", query)
+}
@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+const HN_SEARCH_URL = "https://hn.algolia.com/api/v1"
+
+var (
+	hackerNewsInstance *HackerNewsClient
+	hackerNewsOnce     sync.Once
+)
+
+// HackerNewsClient queries the Algolia Hacker News search API, BaseURL can be
+// pointed at a local stub through HN_API_URL
+type HackerNewsClient struct {
+	Client  *http.Client
+	BaseURL string
+}
+
+func GetHackerNewsClient() *HackerNewsClient {
+	hackerNewsOnce.Do(func() {
+		baseURL := os.Getenv("HN_API_URL")
+		if baseURL == "" {
+			baseURL = HN_SEARCH_URL
+		}
+
+		hackerNewsInstance = &HackerNewsClient{
+			Client: &http.Client{
+				Timeout: 30 * time.Second,
+			},
+			BaseURL: baseURL,
+		}
+	})
+	return hackerNewsInstance
+}
+
+// Search returns the stories and comments matching a query created after since,
+// newest first
+func (hn *HackerNewsClient) Search(ctx context.Context, query string, page int, since time.Time) (models.HNSearchResponse, error) {
+	var response models.HNSearchResponse
+
+	parsedUrl, err := url.Parse(hn.BaseURL + "/search_by_date")
+	if err != nil {
+		return response, fmt.Errorf("[HackerNewsClient] Failed to parse URL: %w", err)
+	}
+
+	queryParams := parsedUrl.Query()
+	queryParams.Add("query", query)
+	queryParams.Add("tags", "(story,comment)")
+	queryParams.Add("numericFilters", fmt.Sprintf("created_at_i>%d", since.Unix()))
+	queryParams.Add("hitsPerPage", "100")
+	queryParams.Add("page", fmt.Sprintf("%d", page))
+	parsedUrl.RawQuery = queryParams.Encode()
+
+	backoff := INITIAL_BACKOFF
+	for attempt := 1; attempt <= MAX_RETRIES; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedUrl.String(), nil)
+		if err != nil {
+			return response, fmt.Errorf("[HackerNewsClient] Failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", USER_AGENT)
+
+		resp, err := hn.Client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return response, fmt.Errorf("[HackerNewsClient] Failed to read response: %w", readErr)
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				return response, fmt.Errorf("[HackerNewsClient] Failed to parse response: %w", err)
+			}
+			return response, nil
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return response, fmt.Errorf("[HackerNewsClient] Unexpected status code %d", resp.StatusCode)
+			}
+		}
+
+		slog.Warn("[HackerNewsClient] Search request failed, retrying...",
+			slog.String("query", query),
+			slog.Int("attempt", attempt),
+			slog.String("error", errMsg(err, resp)))
+
+		select {
+		case <-ctx.Done():
+			return response, ctx.Err()
+		case <-time.After(backoff):
+			backoff = minDuration(backoff*2, MAX_BACKOFF)
+		}
+	}
+
+	return response, fmt.Errorf("[HackerNewsClient] Max retries reached for query %s", query)
+}
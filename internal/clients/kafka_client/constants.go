@@ -3,10 +3,11 @@ package kafka_client
 import "time"
 
 const (
-	KAFKA_TOPIC_RAW_CONTENT       = "raw-content"       // data from multiple content outlets
-	KAFKA_TOPIC_SUMMARY_REQUEST   = "summary-request"   // longer content that will need to be summarized before processing
-	KAFKA_TOPIC_SENTIMENT_REQUEST = "sentiment-request" // batched messages to be sent for analysis
-	KAFKA_TOPIC_SENTIMENT_RESULTS = "sentiment-results" // batched results from sentiment analysis
+	KAFKA_TOPIC_RAW_CONTENT             = "raw-content"             // data from multiple content outlets
+	KAFKA_TOPIC_RAW_CONTENT_UNSUPPORTED = "raw-content-unsupported" // content in a language the sentiment model doesn't support
+	KAFKA_TOPIC_SUMMARY_REQUEST         = "summary-request"         // longer content that will need to be summarized before processing
+	KAFKA_TOPIC_SENTIMENT_REQUEST       = "sentiment-request"       // batched messages to be sent for analysis
+	KAFKA_TOPIC_SENTIMENT_RESULTS       = "sentiment-results"       // batched results from sentiment analysis
 )
 
 const (
@@ -17,6 +17,8 @@ import (
 var (
 	producer *kafka.Producer
 	initOnce sync.Once
+	// publishMu serializes transactions, a producer can only have one open at a time
+	publishMu sync.Mutex
 )
 
 func generateTransactionalID(baseID string) string {
@@ -95,6 +97,9 @@ func CloseProducer() {
 
 // PublishToKafka sends a Reddit post to Kafka
 func PublishToKafka(ctx context.Context, topic string, message interface{}) error {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
 	const maxBeginRetries = 5
 	for i := 0; i < maxBeginRetries; i++ {
 		select {
@@ -0,0 +1,240 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// Mastodon's default budget is 300 requests every 5 minutes per instance
+const (
+	MASTODON_RATE_LIMIT_BURST = 10
+	MASTODON_RATE_LIMIT       = 300
+	MASTODON_RATE_WINDOW      = 5 * time.Minute
+)
+
+var (
+	mastodonClientInstance *MastodonClient
+	mastodonClientOnce     sync.Once
+)
+
+// MastodonClient queries the public search and hashtag timelines of any number
+// of instances, each instance gets its own rate limiter
+type MastodonClient struct {
+	Client      *http.Client
+	AccessToken string
+	limiters    map[string]*RateLimiter
+	mu          sync.Mutex
+}
+
+func GetMastodonClient() *MastodonClient {
+	mastodonClientOnce.Do(func() {
+		mastodonClientInstance = &MastodonClient{
+			Client: &http.Client{
+				Timeout: 30 * time.Second,
+			},
+			AccessToken: os.Getenv("MASTODON_ACCESS_TOKEN"),
+			limiters:    make(map[string]*RateLimiter),
+		}
+	})
+	return mastodonClientInstance
+}
+
+// SearchStatuses runs a full text status search on an instance, paging
+// backwards from maxID when it is set
+func (mc *MastodonClient) SearchStatuses(ctx context.Context, instance, query, maxID string, limit int) ([]models.MastodonStatus, error) {
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("type", "statuses")
+	params.Add("limit", strconv.Itoa(limit))
+	if maxID != "" {
+		params.Add("max_id", maxID)
+	}
+
+	rawData, err := mc.get(ctx, instance, "/api/v2/search", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.MastodonSearchResponse
+	if err := json.Unmarshal(rawData, &response); err != nil {
+		return nil, fmt.Errorf("[MastodonClient] Failed to parse search response: %w", err)
+	}
+	return response.Statuses, nil
+}
+
+// HashtagTimeline returns the public statuses for a hashtag newer than sinceID,
+// paging backwards from maxID when it is set
+func (mc *MastodonClient) HashtagTimeline(ctx context.Context, instance, hashtag, maxID, sinceID string, limit int) ([]models.MastodonStatus, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	if maxID != "" {
+		params.Add("max_id", maxID)
+	}
+	if sinceID != "" {
+		params.Add("since_id", sinceID)
+	}
+
+	rawData, err := mc.get(ctx, instance, "/api/v1/timelines/tag/"+url.PathEscape(hashtag), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []models.MastodonStatus
+	if err := json.Unmarshal(rawData, &statuses); err != nil {
+		return nil, fmt.Errorf("[MastodonClient] Failed to parse hashtag timeline: %w", err)
+	}
+	return statuses, nil
+}
+
+func (mc *MastodonClient) get(ctx context.Context, instance, path string, params url.Values) ([]byte, error) {
+	endpoint := strings.TrimRight(instance, "/") + path + "?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[MastodonClient] Failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", USER_AGENT)
+	if mc.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+mc.AccessToken)
+	}
+
+	return mc.doRequestWithBackoff(ctx, req, instance)
+}
+
+func (mc *MastodonClient) limiter(instance string) *RateLimiter {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	limiter, ok := mc.limiters[instance]
+	if !ok {
+		limiter = NewRateLimiter(MASTODON_RATE_LIMIT_BURST, MASTODON_RATE_LIMIT, MASTODON_RATE_WINDOW)
+		mc.limiters[instance] = limiter
+	}
+	return limiter
+}
+
+// ThrottledTime returns the total time requests have spent waiting on the
+// instance rate limiters
+func (mc *MastodonClient) ThrottledTime() time.Duration {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	var total time.Duration
+	for _, limiter := range mc.limiters {
+		total += limiter.ThrottledTime()
+	}
+	return total
+}
+
+// doRequestWithBackoff executes the request with retry logic, pacing it through
+// the instances rate limiter
+func (mc *MastodonClient) doRequestWithBackoff(ctx context.Context, req *http.Request, instance string) ([]byte, error) {
+	limiter := mc.limiter(instance)
+	backoff := INITIAL_BACKOFF
+
+	for i := 0; i < MAX_RETRIES; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := mc.Client.Do(req)
+		if err != nil {
+			slog.Warn("[MastodonClient] Request error, retrying...",
+				slog.String("instance", instance),
+				slog.Int("attempt", i+1),
+				slog.String("error", err.Error()))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+				backoff = minDuration(backoff*2, MAX_BACKOFF)
+				continue
+			}
+		}
+
+		remaining, reset := parseMastodonRateLimitHeaders(resp)
+		limiter.Update(remaining, reset)
+		if remaining <= 1 {
+			slog.Warn("[MastodonClient] Approaching rate limit. Pacing requests until reset...",
+				slog.String("instance", instance),
+				slog.Duration("reset", reset))
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return body, err
+
+		case http.StatusTooManyRequests:
+			resp.Body.Close()
+			slog.Warn("[MastodonClient] 429 Too Many Requests - Backing off and retrying...",
+				slog.String("instance", instance))
+			wait := max(backoff, reset)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+				backoff = minDuration(backoff*2, MAX_BACKOFF)
+			}
+
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			resp.Body.Close()
+			return nil, fmt.Errorf("[MastodonClient] Request to %s rejected with status %d", instance, resp.StatusCode)
+
+		default:
+			resp.Body.Close()
+			slog.Warn("[MastodonClient] Unexpected status code",
+				slog.String("instance", instance),
+				slog.Int("status", resp.StatusCode))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+				backoff = minDuration(backoff*2, MAX_BACKOFF)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("[MastodonClient] Max retries reached for instance %s", instance)
+}
+
+// parseMastodonRateLimitHeaders extracts Mastodon's rate limit headers, unlike
+// Reddit the reset is sent as a timestamp
+func parseMastodonRateLimitHeaders(resp *http.Response) (remaining int, reset time.Duration) {
+	remaining = MASTODON_RATE_LIMIT
+	reset = MASTODON_RATE_WINDOW
+
+	if val := resp.Header.Get("X-RateLimit-Remaining"); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			remaining = parsed
+		} else {
+			slog.Warn("[MastodonClient] Failed to parse X-RateLimit-Remaining", slog.String("error", err.Error()))
+		}
+		if remaining < 1 {
+			remaining = 1
+		}
+	}
+
+	if val := resp.Header.Get("X-RateLimit-Reset"); val != "" {
+		if resetAt, err := time.Parse(time.RFC3339, val); err == nil {
+			reset = max(time.Until(resetAt), time.Second)
+		} else {
+			slog.Warn("[MastodonClient] Failed to parse X-RateLimit-Reset", slog.String("error", err.Error()))
+		}
+	}
+
+	return
+}
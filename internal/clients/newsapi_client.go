@@ -7,15 +7,23 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
 )
 
 const (
-	NEWS_API_ENDPOINT = "https://newsapi.org/v2/top-headlines?country=us&pageSize=100&apiKey="
+	NEWS_API_BASE_URL      = "https://newsapi.org/v2"
+	NEWS_API_TOP_HEADLINES = "top-headlines"
+	NEWS_API_EVERYTHING    = "everything"
+	NEWS_API_PAGE_SIZE     = 100
+	NEWS_API_LOOKBACK      = 24 * time.Hour
 )
 
 var (
@@ -26,179 +34,254 @@ var (
 type NewsAPIClient struct {
 	Client *http.Client
 	APIKey string
+
+	// Countries scope top-headlines, Languages scope /everything since
+	// top-headlines has no language filter
+	Countries []string
+	Languages []string
+
+	// Queries are sent to /everything, restricted to Domains when set
+	Queries []string
+	Domains []string
+
+	// From and To bound /everything queries, From defaults to Lookback ago
+	From     string
+	To       string
+	Lookback time.Duration
+
+	MaxPages int
+}
+
+// newsAPIRequest is a single paginated query against NewsAPI, origin is
+// recorded on every article so topics can be traced back to the request
+type newsAPIRequest struct {
+	endpoint string
+	params   url.Values
+	origin   models.NewsAPIOrigin
 }
 
 func GetNewsAPIClient() *NewsAPIClient {
 	newsAPIOnce.Do(func() {
+		lookback := NEWS_API_LOOKBACK
+		if val := os.Getenv("NEWS_API_LOOKBACK"); val != "" {
+			if parsed, err := time.ParseDuration(val); err == nil {
+				lookback = parsed
+			}
+		}
+
+		maxPages := 1
+		if val := os.Getenv("NEWS_API_MAX_PAGES"); val != "" {
+			if parsed, err := strconv.Atoi(val); err == nil && parsed > 0 {
+				maxPages = parsed
+			}
+		}
+
+		countries := splitNewsAPIList(os.Getenv("NEWS_API_COUNTRIES"), ",")
+		languages := splitNewsAPIList(os.Getenv("NEWS_API_LANGUAGES"), ",")
+		if len(countries) == 0 {
+			countries = []string{"us"}
+		}
+
 		newsAPIInstance = &NewsAPIClient{
-			Client: &http.Client{},
-			APIKey: os.Getenv("NEWS_API_KEY"),
+			Client:    &http.Client{},
+			APIKey:    os.Getenv("NEWS_API_KEY"),
+			Countries: countries,
+			Languages: languages,
+			// Queries may contain commas in boolean expressions so they are split on ;
+			Queries:  splitNewsAPIList(os.Getenv("NEWS_API_QUERIES"), ";"),
+			Domains:  splitNewsAPIList(os.Getenv("NEWS_API_DOMAINS"), ","),
+			From:     os.Getenv("NEWS_API_FROM"),
+			To:       os.Getenv("NEWS_API_TO"),
+			Lookback: lookback,
+			MaxPages: maxPages,
 		}
 	})
 	return newsAPIInstance
 }
 
-func (n NewsAPIClient) GetTopHeadlinesByCategory() ([]models.NewsAPIArticles, error) {
+// GetArticles fetches top headlines for every configured country and, when
+// queries are configured, the matching articles from /everything
+func (n NewsAPIClient) GetArticles() ([]models.NewsAPIArticles, error) {
 	if n.APIKey == "" {
 		slog.Error("[NewsAPIClient] API key is missing")
 		return nil, errors.New("[NewsAPIClient] API key is missing")
 	}
-
-	var responses []models.NewsAPIArticles
-	categories := []string{
-		"business", "entertainment", "general",
-		"health", "science", "sports", "technology",
+	if len(n.Languages) > 0 && len(n.Queries) == 0 {
+		return nil, errors.New("[NewsAPIClient] NEWS_API_LANGUAGES only applies to NEWS_API_QUERIES, which is not set")
 	}
 
-	for _, category := range categories {
-		slog.Info("[NewsAPIClient] Fetching headlines", slog.String("category", category))
+	requests := append(n.topHeadlinesRequests(), n.everythingRequests()...)
+	articles := n.fetchRequests(requests)
 
-		response, err := n.fetchCategoryHeadlinesByCategory(category)
-		if err != nil {
-			slog.Warn("[NewsAPIClient] Skipping category due to repeated failures",
-				slog.String("category", category), slog.String("error", err.Error()))
-			continue
-		}
-
-		slog.Debug("[NewsAPIClient] Number of headlines in this request",
-			slog.Int("headlines", len(response.Articles)), slog.String("category", category))
-		responses = append(responses, response.Articles...)
-	}
-
-	if len(responses) == 0 {
+	if len(articles) == 0 {
 		return nil, errors.New("[NewsAPIClient] No results fetched")
 	}
-	return responses, nil
+	return articles, nil
 }
 
-// fetchCategoryHeadlines fetches headlines for a single category with retries
-func (n NewsAPIClient) fetchCategoryHeadlinesByCategory(category string) (*models.NewsAPITopHeadlinesResponse, error) {
-	url := NEWS_API_ENDPOINT + n.APIKey + "&category=" + category
-	backoff := INITIAL_BACKOFF
+// topHeadlinesRequests builds a request per category for each country
+func (n NewsAPIClient) topHeadlinesRequests() []newsAPIRequest {
+	var requests []newsAPIRequest
+	categories := taxonomy.Get().NewsAPICategories()
 
-	for attempt := 1; attempt <= MAX_RETRIES; attempt++ {
-		slog.Info("[NewsAPIClient] Attempting request", slog.String("category", category), slog.Int("attempt", attempt))
-
-		req, err := http.NewRequest(http.MethodGet, url, nil)
-		if err != nil {
-			slog.Error("[NewsAPIClient] Failed to create request", slog.String("error", err.Error()))
-			return nil, err
+	for _, category := range categories {
+		for _, country := range n.Countries {
+			params := url.Values{}
+			params.Set("country", country)
+			params.Set("category", category)
+			requests = append(requests, newsAPIRequest{
+				endpoint: NEWS_API_TOP_HEADLINES,
+				params:   params,
+				origin: models.NewsAPIOrigin{
+					Endpoint: NEWS_API_TOP_HEADLINES,
+					Category: category,
+					Country:  country,
+				},
+			})
 		}
+	}
+	return requests
+}
 
-		res, err := n.Client.Do(req)
-		if err != nil {
-			slog.Error("[NewsAPIClient] Request failed", slog.String("error", err.Error()))
-			if attempt < MAX_RETRIES {
-				time.Sleep(backoff)
-				backoff *= 2
-				if backoff > MAX_BACKOFF {
-					backoff = MAX_BACKOFF
-				}
-			}
-			continue
-		}
-		defer res.Body.Close()
+// everythingRequests builds a request for each query, once per language when
+// languages are configured
+func (n NewsAPIClient) everythingRequests() []newsAPIRequest {
+	from := n.From
+	if from == "" && n.Lookback > 0 {
+		from = time.Now().UTC().Add(-n.Lookback).Format(time.RFC3339)
+	}
 
-		// Read response body
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			slog.Error("[NewsAPIClient] Failed to read response body", slog.String("error", err.Error()))
-			return nil, err
-		}
+	languages := n.Languages
+	if len(languages) == 0 {
+		languages = []string{""}
+	}
 
-		switch res.StatusCode {
-		case http.StatusOK:
-			var response models.NewsAPITopHeadlinesResponse
-			err = json.Unmarshal(body, &response)
-			if err != nil {
-				slog.Error("[NewsAPIClient] Failed to parse JSON", slog.String("error", err.Error()))
-				return nil, err
+	var requests []newsAPIRequest
+	for _, query := range n.Queries {
+		for _, language := range languages {
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("sortBy", "publishedAt")
+			if language != "" {
+				params.Set("language", language)
+			}
+			if len(n.Domains) > 0 {
+				params.Set("domains", strings.Join(n.Domains, ","))
+			}
+			if from != "" {
+				params.Set("from", from)
+			}
+			if n.To != "" {
+				params.Set("to", n.To)
 			}
-			slog.Info("[NewsAPIClient] Successfully fetched headlines", slog.String("category", category))
-			return &response, nil
 
-		case http.StatusBadRequest:
-			return nil, errors.New("[NewsAPIClient] Bad request, check query parameters")
+			requests = append(requests, newsAPIRequest{
+				endpoint: NEWS_API_EVERYTHING,
+				params:   params,
+				origin: models.NewsAPIOrigin{
+					Endpoint: NEWS_API_EVERYTHING,
+					Query:    query,
+					Language: language,
+				},
+			})
+		}
+	}
+	return requests
+}
 
-		case http.StatusUnauthorized:
-			return nil, errors.New("[NewsAPIClient] Invalid API Key, check credentials")
+// fetchRequests runs every request, skipping the ones that keep failing, and
+// drops articles already returned by an earlier request
+func (n NewsAPIClient) fetchRequests(requests []newsAPIRequest) []models.NewsAPIArticles {
+	var articles []models.NewsAPIArticles
+	seen := make(map[string]struct{})
 
-		case http.StatusTooManyRequests:
-			slog.Warn("[NewsAPIClient] Rate limit exceeded, retrying...",
-				slog.Duration("backoff", backoff), slog.Int("attempt", attempt))
-			if attempt < MAX_RETRIES {
-				time.Sleep(backoff)
-				backoff *= 2
-				if backoff > MAX_BACKOFF {
-					backoff = MAX_BACKOFF
-				}
-			}
+	for _, request := range requests {
+		slog.Info("[NewsAPIClient] Fetching articles",
+			slog.String("endpoint", request.endpoint),
+			slog.String("params", request.params.Encode()))
+
+		fetched, err := n.fetchAllPages(request)
+		if err != nil {
+			slog.Warn("[NewsAPIClient] Skipping request due to repeated failures",
+				slog.String("endpoint", request.endpoint),
+				slog.String("params", request.params.Encode()),
+				slog.String("error", err.Error()))
 			continue
+		}
 
-		case http.StatusForbidden:
-			return nil, errors.New("[NewsAPIClient] API key lacks required permissions")
+		slog.Debug("[NewsAPIClient] Number of articles in this request",
+			slog.Int("articles", len(fetched)), slog.String("endpoint", request.endpoint))
 
-		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
-			slog.Warn("[NewsAPIClient] Server error, retrying...",
-				slog.Int("statusCode", res.StatusCode), slog.Duration("backoff", backoff), slog.Int("attempt", attempt))
-			if attempt < MAX_RETRIES {
-				time.Sleep(backoff)
-				backoff *= 2
-				if backoff > MAX_BACKOFF {
-					backoff = MAX_BACKOFF
-				}
+		for _, article := range fetched {
+			if _, ok := seen[article.URL]; ok {
+				continue
 			}
-			continue
-
-		default:
-			slog.Warn("[NewsAPIClient] Unexpected response",
-				slog.String("category", category), slog.Int("statusCode", res.StatusCode))
-			return nil, errors.New("[NewsAPIClient] Unexpected status code")
+			seen[article.URL] = struct{}{}
+			articles = append(articles, article)
 		}
 	}
-
-	slog.Error("[NewsAPIClient] Failed after max retries", slog.String("category", category))
-	return nil, errors.New("[NewsAPIClient] Failed after max retries")
+	return articles
 }
 
-func (n NewsAPIClient) GetTopHeadlines() ([]models.NewsAPIArticles, error) {
-	if n.APIKey == "" {
-		slog.Error("[NewsAPIClient] API key is missing")
-		return nil, errors.New("[NewsAPIClient] API key is missing")
-	}
-
-	var responses []models.NewsAPIArticles
+// fetchAllPages pages through a request until all results are read or
+// MaxPages is reached
+func (n NewsAPIClient) fetchAllPages(request newsAPIRequest) ([]models.NewsAPIArticles, error) {
+	var articles []models.NewsAPIArticles
+
+	for page := 1; page <= n.MaxPages; page++ {
+		response, err := n.fetchPage(request, page)
+		if errors.Is(err, ErrNewsAPIPageLimit) && page > 1 {
+			slog.Warn("[NewsAPIClient] Plan does not allow further pages",
+				slog.String("endpoint", request.endpoint), slog.Int("page", page))
+			break
+		}
+		if err != nil {
+			if len(articles) > 0 {
+				slog.Warn("[NewsAPIClient] Stopping pagination early",
+					slog.Int("page", page), slog.String("error", err.Error()))
+				break
+			}
+			return nil, err
+		}
 
-	slog.Info("[NewsAPIClient] Fetching headlines")
+		for _, article := range response.Articles {
+			article.Origin = request.origin
+			articles = append(articles, article)
+		}
 
-	response, err := n.fetchCategoryHeadlines()
-	if err != nil {
-		slog.Warn("[NewsAPIClient] Skipping category due to repeated failures",
-			slog.String("error", err.Error()))
+		if len(response.Articles) < NEWS_API_PAGE_SIZE || page*NEWS_API_PAGE_SIZE >= response.TotalResults {
+			break
+		}
 	}
+	return articles, nil
+}
 
-	slog.Debug("[NewsAPIClient] Number of headlines in this request",
-		slog.Int("headlines", len(response.Articles)))
-	responses = append(responses, response.Articles...)
+// ErrNewsAPIPageLimit is returned when the plan does not allow requesting a page
+var ErrNewsAPIPageLimit = errors.New("[NewsAPIClient] Page limit reached for this plan")
 
-	if len(responses) == 0 {
-		return nil, errors.New("[NewsAPIClient] No results fetched")
+// fetchPage fetches a single page of a request with retries
+func (n NewsAPIClient) fetchPage(request newsAPIRequest, page int) (*models.NewsAPITopHeadlinesResponse, error) {
+	params := url.Values{}
+	for key, values := range request.params {
+		params[key] = values
 	}
-	return responses, nil
-}
+	params.Set("pageSize", strconv.Itoa(NEWS_API_PAGE_SIZE))
+	params.Set("page", strconv.Itoa(page))
 
-func (n NewsAPIClient) fetchCategoryHeadlines() (*models.NewsAPITopHeadlinesResponse, error) {
-	url := NEWS_API_ENDPOINT + n.APIKey
+	endpoint := NEWS_API_BASE_URL + "/" + request.endpoint + "?" + params.Encode()
 	backoff := INITIAL_BACKOFF
 
 	for attempt := 1; attempt <= MAX_RETRIES; attempt++ {
-		slog.Info("[NewsAPIClient] Attempting request", slog.Int("attempt", attempt))
+		slog.Info("[NewsAPIClient] Attempting request",
+			slog.String("endpoint", request.endpoint), slog.Int("page", page), slog.Int("attempt", attempt))
 
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
 		if err != nil {
 			slog.Error("[NewsAPIClient] Failed to create request", slog.String("error", err.Error()))
 			return nil, err
 		}
+		// Sent as a header so the key never ends up in logged URLs
+		req.Header.Set("X-Api-Key", n.APIKey)
 
 		res, err := n.Client.Do(req)
 		if err != nil {
@@ -212,10 +295,10 @@ func (n NewsAPIClient) fetchCategoryHeadlines() (*models.NewsAPITopHeadlinesResp
 			}
 			continue
 		}
-		defer res.Body.Close()
 
 		// Read response body
 		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
 		if err != nil {
 			slog.Error("[NewsAPIClient] Failed to read response body", slog.String("error", err.Error()))
 			return nil, err
@@ -229,15 +312,20 @@ func (n NewsAPIClient) fetchCategoryHeadlines() (*models.NewsAPITopHeadlinesResp
 				slog.Error("[NewsAPIClient] Failed to parse JSON", slog.String("error", err.Error()))
 				return nil, err
 			}
-			slog.Info("[NewsAPIClient] Successfully fetched headlines")
+			slog.Info("[NewsAPIClient] Successfully fetched articles",
+				slog.String("endpoint", request.endpoint), slog.Int("page", page),
+				slog.Int("total_results", response.TotalResults))
 			return &response, nil
 
 		case http.StatusBadRequest:
-			return nil, errors.New("[NewsAPIClient] Bad request, check query parameters")
+			return nil, fmt.Errorf("[NewsAPIClient] Bad request, check query parameters: %s", newsAPIErrorMessage(body))
 
 		case http.StatusUnauthorized:
 			return nil, errors.New("[NewsAPIClient] Invalid API Key, check credentials")
 
+		case http.StatusUpgradeRequired:
+			return nil, fmt.Errorf("%w: %s", ErrNewsAPIPageLimit, newsAPIErrorMessage(body))
+
 		case http.StatusTooManyRequests:
 			slog.Warn("[NewsAPIClient] Rate limit exceeded, retrying...",
 				slog.Duration("backoff", backoff), slog.Int("attempt", attempt))
@@ -267,15 +355,35 @@ func (n NewsAPIClient) fetchCategoryHeadlines() (*models.NewsAPITopHeadlinesResp
 
 		default:
 			slog.Warn("[NewsAPIClient] Unexpected response",
-				slog.Int("statusCode", res.StatusCode))
+				slog.String("endpoint", request.endpoint), slog.Int("statusCode", res.StatusCode))
 			return nil, errors.New("[NewsAPIClient] Unexpected status code")
 		}
 	}
 
-	slog.Error("[NewsAPIClient] Failed after max retries")
+	slog.Error("[NewsAPIClient] Failed after max retries", slog.String("endpoint", request.endpoint))
 	return nil, errors.New("[NewsAPIClient] Failed after max retries")
 }
 
+// newsAPIErrorMessage extracts the message from a NewsAPI error body
+func newsAPIErrorMessage(body []byte) string {
+	var apiErr models.NewsAPIError
+	if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Message == "" {
+		return string(body)
+	}
+	return apiErr.Code + ": " + apiErr.Message
+}
+
+// splitNewsAPIList splits a separated env value, dropping empty entries
+func splitNewsAPIList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (n NewsAPIClient) GetTopHeadlinesFromFile() ([]models.NewsAPIArticles, error) {
 	var response models.NewsAPITopHeadlinesResponse
 	var headlines []models.NewsAPIArticles
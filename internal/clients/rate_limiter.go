@@ -0,0 +1,136 @@
+package clients
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter is a token bucket shared by every caller of an API. Its refill
+// rate starts from a static budget and is then retuned from the rate limit
+// headers the API sends back, so callers are paced across the whole window
+// instead of bursting until the budget runs out.
+type RateLimiter struct {
+	mu        sync.Mutex
+	tokens    float64
+	capacity  float64
+	rate      float64 // tokens per second
+	last      time.Time
+	blocked   time.Time // no tokens are handed out before this
+	throttled atomic.Int64
+}
+
+func NewRateLimiter(capacity int, requests int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		tokens:   float64(capacity),
+		capacity: float64(capacity),
+		rate:     float64(requests) / window.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or the context is done
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		rl.mu.Lock()
+		rl.refill()
+		if rl.tokens >= 1 && !time.Now().Before(rl.blocked) {
+			rl.tokens--
+			rl.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+		if blockedFor := time.Until(rl.blocked); blockedFor > 0 {
+			wait = blockedFor
+		}
+		rl.mu.Unlock()
+
+		start := time.Now()
+		select {
+		case <-ctx.Done():
+			rl.throttled.Add(int64(time.Since(start)))
+			return ctx.Err()
+		case <-time.After(wait):
+			rl.throttled.Add(int64(time.Since(start)))
+		}
+	}
+}
+
+// Allow takes a token if one is available without waiting
+func (rl *RateLimiter) Allow() bool {
+	return rl.AllowN(1)
+}
+
+// AllowN takes n tokens if they are all available without waiting
+func (rl *RateLimiter) AllowN(n int) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill()
+	if rl.tokens >= float64(n) && !time.Now().Before(rl.blocked) {
+		rl.tokens -= float64(n)
+		return true
+	}
+	return false
+}
+
+// Capacity is the most tokens the bucket ever holds
+func (rl *RateLimiter) Capacity() int {
+	return int(rl.capacity)
+}
+
+// Update retunes the bucket from the remaining requests and the time until the
+// API resets its window
+func (rl *RateLimiter) Update(remaining int, reset time.Duration) {
+	if reset <= 0 {
+		return
+	}
+	if remaining < 1 {
+		remaining = 1
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill()
+	rl.rate = float64(remaining) / reset.Seconds()
+	if rl.tokens > float64(remaining) {
+		rl.tokens = float64(remaining)
+	}
+}
+
+// BlockUntil drains the bucket and hands out no tokens before until, for when
+// the API reports the budget is spent and the window has to be waited out.
+// A single token is waiting at until so the first request after the reset
+// goes out right away and retunes the bucket from the fresh headers.
+func (rl *RateLimiter) BlockUntil(until time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.tokens = 1
+	rl.last = until
+	if until.After(rl.blocked) {
+		rl.blocked = until
+	}
+}
+
+// ThrottledTime returns the total time callers have spent waiting on the limiter
+func (rl *RateLimiter) ThrottledTime() time.Duration {
+	return time.Duration(rl.throttled.Load())
+}
+
+func (rl *RateLimiter) refill() {
+	now := time.Now()
+	if now.Before(rl.blocked) {
+		return
+	}
+	if now.Before(rl.last) {
+		rl.last = now
+	}
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > rl.capacity {
+		rl.tokens = rl.capacity
+	}
+	rl.last = now
+}
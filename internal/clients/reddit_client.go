@@ -26,7 +26,10 @@ const (
 
 // Concurrency & Rate Limits
 const (
-	MAX_CONCURRENT_WORKERS = 2 // Limit number of parallel API calls
+	MAX_CONCURRENT_WORKERS  = 2               // Limit number of parallel API calls
+	REDDIT_RATE_LIMIT_BURST = 10              // Requests that can go out back to back
+	REDDIT_RATE_LIMIT       = 100             // Requests allowed per window until headers say otherwise
+	REDDIT_RATE_WINDOW      = 1 * time.Minute // Window REDDIT_RATE_LIMIT applies to
 )
 
 // Singleton Reddit Client
@@ -40,7 +43,8 @@ type RedditClient struct {
 	Config       *clientcredentials.Config
 	Client       *http.Client
 	WorkerTokens chan struct{} // Semaphore for limiting workers
-	mu           *sync.Mutex
+	Limiter      *RateLimiter  // Paces requests across every worker
+	mu           *sync.RWMutex // Guards Client, which RefreshClient replaces
 }
 
 // GetRedditClient returns a singleton RedditClient instance
@@ -57,14 +61,21 @@ func GetRedditClient() *RedditClient {
 			Config:       oauthConf,
 			Client:       oauthConf.Client(context.Background()),
 			WorkerTokens: make(chan struct{}, MAX_CONCURRENT_WORKERS),
-			mu:           &sync.Mutex{},
+			Limiter:      NewRateLimiter(REDDIT_RATE_LIMIT_BURST, REDDIT_RATE_LIMIT, REDDIT_RATE_WINDOW),
+			mu:           &sync.RWMutex{},
 		}
 	})
 
 	return redditClientInstance
 }
 
-func buildRedditAPIUrl(subreddit, topic, after string) (string, error) {
+// Reddit search sort orders
+const (
+	REDDIT_SORT_RELEVANCE = "relevance"
+	REDDIT_SORT_NEW       = "new"
+)
+
+func buildRedditAPIUrl(subreddit, topic, after, sort string) (string, error) {
 	parsedUrl, err := url.Parse(fmt.Sprintf("%s/r/%s/search", REDDIT_API_URL, subreddit))
 	if err != nil {
 		return "", fmt.Errorf("[RedditClient] Failed to parse URL: %w", err)
@@ -72,9 +83,14 @@ func buildRedditAPIUrl(subreddit, topic, after string) (string, error) {
 
 	queryParams := parsedUrl.Query()
 	queryParams.Add("q", topic)
-	queryParams.Add("sort", "relevance")
+	queryParams.Add("sort", sort)
 	queryParams.Add("limit", "100")
-	queryParams.Add("t", "day")
+	if sort == REDDIT_SORT_NEW {
+		// newest first across all time, the caller stops paging at its cursor
+		queryParams.Add("t", "all")
+	} else {
+		queryParams.Add("t", "day")
+	}
 	queryParams.Add("type", "link")
 	if after != "" {
 		queryParams.Add("after", after)
@@ -92,13 +108,32 @@ func (rc *RedditClient) RefreshClient() {
 	slog.Info("[RedditClient] Token refreshed successfully")
 }
 
+// httpClient returns the current OAuth2 client, workers read it while a
+// refresh may be replacing it
+func (rc *RedditClient) httpClient() *http.Client {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.Client
+}
+
 // FetchSubredditPosts fetches posts from a subreddit based on a given topic
 func (rc *RedditClient) FetchSubredditPosts(ctx context.Context, subreddit, topic, after string) ([]models.RedditPost, string, error) {
+	return rc.searchSubredditPosts(ctx, subreddit, topic, after, REDDIT_SORT_RELEVANCE)
+}
+
+// FetchNewSubredditPosts fetches posts from a subreddit based on a given topic,
+// newest first
+func (rc *RedditClient) FetchNewSubredditPosts(ctx context.Context, subreddit, topic, after string) ([]models.RedditPost, string, error) {
+	return rc.searchSubredditPosts(ctx, subreddit, topic, after, REDDIT_SORT_NEW)
+}
+
+func (rc *RedditClient) searchSubredditPosts(ctx context.Context, subreddit, topic, after, sort string) ([]models.RedditPost, string, error) {
 	slog.Info("[RedditClient] Requesting data from reddit",
 		slog.String("subreddits", subreddit),
-		slog.String("topic", topic))
+		slog.String("topic", topic),
+		slog.String("sort", sort))
 
-	url, err := buildRedditAPIUrl(subreddit, topic, after)
+	url, err := buildRedditAPIUrl(subreddit, topic, after, sort)
 	if err != nil {
 		return nil, "", err
 	}
@@ -136,17 +171,112 @@ func (rc *RedditClient) FetchSubredditPosts(ctx context.Context, subreddit, topi
 	return posts, nextAfter, nil
 }
 
+func buildRedditCommentsUrl(postID string, limit, depth int) (string, error) {
+	parsedUrl, err := url.Parse(fmt.Sprintf("%s/comments/%s", REDDIT_API_URL, postID))
+	if err != nil {
+		return "", fmt.Errorf("[RedditClient] Failed to parse URL: %w", err)
+	}
+
+	queryParams := parsedUrl.Query()
+	queryParams.Add("sort", "top")
+	queryParams.Add("limit", fmt.Sprintf("%d", limit))
+	queryParams.Add("depth", fmt.Sprintf("%d", depth))
+	parsedUrl.RawQuery = queryParams.Encode()
+
+	return parsedUrl.String(), nil
+}
+
+// FetchPostComments fetches the top comments of a post, walking the comment tree
+// up to depth levels and returning at most limit comments
+func (rc *RedditClient) FetchPostComments(ctx context.Context, post models.RedditPost, limit, depth int) ([]models.RedditComment, error) {
+	url, err := buildRedditCommentsUrl(post.PostID, limit, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", USER_AGENT)
+
+	start := time.Now()
+	rawData, err := rc.doRequestWithBackoff(ctx, req, post.Subreddit)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := parseRedditCommentsResponse(rawData, post, limit, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("[RedditClient] Comment response details",
+		slog.String("post_id", post.PostID),
+		slog.String("topic", post.Topic),
+		slog.Int("comment_count", len(comments)),
+		slog.Duration("time_elapsed", time.Since(start)))
+
+	return comments, nil
+}
+
+// SearchSubreddits returns the names of the subreddits Reddit considers most
+// relevant to a query, NSFW subreddits are left out
+func (rc *RedditClient) SearchSubreddits(ctx context.Context, query string, limit int) ([]string, error) {
+	parsedUrl, err := url.Parse(fmt.Sprintf("%s/subreddits/search", REDDIT_API_URL))
+	if err != nil {
+		return nil, fmt.Errorf("[RedditClient] Failed to parse URL: %w", err)
+	}
+
+	queryParams := parsedUrl.Query()
+	queryParams.Add("q", query)
+	queryParams.Add("sort", REDDIT_SORT_RELEVANCE)
+	queryParams.Add("limit", fmt.Sprintf("%d", limit))
+	parsedUrl.RawQuery = queryParams.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", parsedUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", USER_AGENT)
+
+	rawData, err := rc.doRequestWithBackoff(ctx, req, "subreddits/search")
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.RedditSubredditSearchResponse
+	if err := json.Unmarshal(rawData, &response); err != nil {
+		slog.Error("[RedditClient] Failed to parse subreddit search response", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	var subreddits []string
+	for _, child := range response.Data.Children {
+		if child.Data.Over18 || child.Data.DisplayName == "" {
+			continue
+		}
+		subreddits = append(subreddits, child.Data.DisplayName)
+	}
+
+	return subreddits, nil
+}
+
 // doRequestWithBackoff executes the request with retry logic and token refresh handling
 func (rc *RedditClient) doRequestWithBackoff(ctx context.Context, req *http.Request, subreddit string) ([]byte, error) {
 	backoff := INITIAL_BACKOFF
 	for i := 0; i < MAX_RETRIES; i++ {
+		if err := rc.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case rc.WorkerTokens <- struct{}{}:
 
 		}
-		resp, err := rc.Client.Do(req)
+		resp, err := rc.httpClient().Do(req)
 		<-rc.WorkerTokens // Release worker slot
 
 		if err != nil {
@@ -163,15 +293,19 @@ func (rc *RedditClient) doRequestWithBackoff(ctx context.Context, req *http.Requ
 		}
 		defer resp.Body.Close()
 
-		// Handle Rate Limit Headers
-		if remaining, reset := parseRateLimitHeaders(resp); remaining <= 1 {
-			slog.Warn("[RedditClient] Approaching rate limit. Sleeping until reset...",
-				slog.Duration("sleep", reset))
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(reset):
-				continue
+		// Handle Rate Limit Headers, the limiter holds every worker back once
+		// the remaining budget runs low
+		remaining, reset := parseRateLimitHeaders(resp)
+		if remaining < 1 {
+			// the budget is spent, every worker waits for the window to reset
+			slog.Warn("[RedditClient] Rate limit exhausted. Waiting until reset...",
+				slog.Duration("reset", reset))
+			rc.Limiter.BlockUntil(time.Now().Add(reset))
+		} else {
+			rc.Limiter.Update(remaining, reset)
+			if remaining <= 1 {
+				slog.Warn("[RedditClient] Approaching rate limit. Pacing requests until reset...",
+					slog.Duration("reset", reset))
 			}
 		}
 
@@ -216,6 +350,11 @@ func minDuration(a, b time.Duration) time.Duration {
 	return b
 }
 
+// ThrottledTime returns the total time requests have spent waiting on the rate limiter
+func (rc *RedditClient) ThrottledTime() time.Duration {
+	return rc.Limiter.ThrottledTime()
+}
+
 // parseRedditResponse parses the JSON response from Reddit API into structured RedditPost objects
 func parseRedditResponse(rawData []byte, topic string) ([]models.RedditPost, string, error) {
 	var redditResponse models.RedditAPIResponse
@@ -228,15 +367,31 @@ func parseRedditResponse(rawData []byte, topic string) ([]models.RedditPost, str
 	var posts []models.RedditPost
 	for _, item := range redditResponse.Data.Children {
 		post := item.Data
+
+		// Self posts link back to themselves, only outbound links are kept
+		var linkURL string
+		if !post.IsSelf {
+			linkURL = post.URL
+		}
+
 		posts = append(posts, models.RedditPost{
-			Topic:       topic,
-			Subreddit:   post.Subreddit,
-			PostTitle:   post.Title,
-			Author:      post.AuthorFullname,
-			PostContent: post.Selftext,
-			Upvotes:     post.Ups,
-			CreatedAt:   time.Unix(int64(post.CreatedUTC), 0),
-			PostID:      post.ID,
+			Topic:         topic,
+			Subreddit:     post.Subreddit,
+			PostTitle:     post.Title,
+			Author:        post.Author,
+			AuthorID:      post.AuthorFullname,
+			PostContent:   post.Selftext,
+			Upvotes:       post.Ups,
+			UpvoteRatio:   post.UpvoteRatio,
+			NumComments:   post.NumComments,
+			Permalink:     post.Permalink,
+			LinkFlairText: post.LinkFlairText,
+			Over18:        post.Over18,
+			Stickied:      post.Stickied,
+			CreatedAt:     time.Unix(int64(post.CreatedUTC), 0),
+			PostID:        post.ID,
+			Fullname:      post.Name,
+			LinkURL:       linkURL,
 		})
 	}
 
@@ -252,8 +407,8 @@ func parseRateLimitHeaders(resp *http.Response) (remaining int, reset time.Durat
 		if _, err := fmt.Sscanf(val, "%d", &remaining); err != nil {
 			slog.Warn("[RedditClient] Failed to parse X-Ratelimit-Remaining", slog.String("error", err.Error()))
 		}
-		if remaining < 1 {
-			remaining = 1 // Prevent divide-by-zero errors
+		if remaining < 0 {
+			remaining = 0
 		}
 	}
 
@@ -267,3 +422,63 @@ func parseRateLimitHeaders(resp *http.Response) (remaining int, reset time.Durat
 
 	return
 }
+
+// parseRedditCommentsResponse flattens the comment tree returned by the
+// /comments/{id} endpoint into RedditComment objects
+func parseRedditCommentsResponse(rawData []byte, post models.RedditPost, limit, depth int) ([]models.RedditComment, error) {
+	var listings []models.RedditCommentListing
+	if err := json.Unmarshal(rawData, &listings); err != nil {
+		slog.Error("[RedditClient] Failed to parse Reddit comments response", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// The first listing is the post itself, the second holds the comments
+	if len(listings) < 2 {
+		return nil, nil
+	}
+
+	var comments []models.RedditComment
+	collectComments(listings[1].Data.Children, post, limit, depth, &comments)
+	return comments, nil
+}
+
+func collectComments(children []models.RedditCommentChild, post models.RedditPost, limit, depth int, comments *[]models.RedditComment) {
+	for _, child := range children {
+		if len(*comments) >= limit {
+			return
+		}
+
+		// "more" children are placeholders for comments that weren't loaded
+		if child.Kind != "t1" {
+			continue
+		}
+
+		comment := child.Data
+		*comments = append(*comments, models.RedditComment{
+			Topic:        post.Topic,
+			Subreddit:    comment.Subreddit,
+			Author:       comment.Author,
+			AuthorID:     comment.AuthorFullname,
+			Body:         comment.Body,
+			Upvotes:      comment.Ups,
+			CreatedAt:    time.Unix(int64(comment.CreatedUTC), 0),
+			CommentID:    comment.ID,
+			ParentPostID: post.PostID,
+			Permalink:    comment.Permalink,
+			Depth:        comment.Depth,
+		})
+
+		if comment.Depth+1 >= depth || len(comment.Replies) == 0 || comment.Replies[0] != '{' {
+			continue
+		}
+
+		var replies models.RedditCommentListing
+		if err := json.Unmarshal(comment.Replies, &replies); err != nil {
+			slog.Warn("[RedditClient] Failed to parse comment replies",
+				slog.String("comment_id", comment.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		collectComments(replies.Data.Children, post, limit, depth, comments)
+	}
+}
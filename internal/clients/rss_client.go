@@ -0,0 +1,211 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/utils"
+)
+
+var (
+	rssClientInstance *RSSClient
+	rssClientOnce     sync.Once
+)
+
+// RSSClient fetches and parses RSS 2.0 and Atom feeds
+type RSSClient struct {
+	Client *http.Client
+}
+
+func GetRSSClient() *RSSClient {
+	rssClientOnce.Do(func() {
+		rssClientInstance = &RSSClient{
+			Client: &http.Client{
+				Timeout: 30 * time.Second,
+			},
+		}
+	})
+	return rssClientInstance
+}
+
+// FetchFeed downloads a feed and returns its entries, the format is detected
+// from the document root
+func (rc *RSSClient) FetchFeed(ctx context.Context, feedURL string) ([]models.FeedItem, error) {
+	backoff := INITIAL_BACKOFF
+
+	for attempt := 1; attempt <= MAX_RETRIES; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("[RSSClient] Failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", USER_AGENT)
+		req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+
+		resp, err := rc.Client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, fmt.Errorf("[RSSClient] Failed to read feed: %w", readErr)
+			}
+			return parseFeed(feedURL, body)
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return nil, fmt.Errorf("[RSSClient] Unexpected status code %d for %s", resp.StatusCode, feedURL)
+			}
+		}
+
+		slog.Warn("[RSSClient] Feed request failed, retrying...",
+			slog.String("feed", feedURL),
+			slog.Int("attempt", attempt),
+			slog.String("error", errMsg(err, resp)))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+			backoff = minDuration(backoff*2, MAX_BACKOFF)
+		}
+	}
+
+	return nil, fmt.Errorf("[RSSClient] Max retries reached for feed %s", feedURL)
+}
+
+func parseFeed(feedURL string, body []byte) ([]models.FeedItem, error) {
+	root, err := feedRoot(body)
+	if err != nil {
+		return nil, fmt.Errorf("[RSSClient] Failed to parse feed %s: %w", feedURL, err)
+	}
+
+	switch root {
+	case "rss":
+		var feed models.RSSFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("[RSSClient] Failed to parse RSS feed %s: %w", feedURL, err)
+		}
+		return rssToFeedItems(feedURL, feed), nil
+	case "feed":
+		var feed models.AtomFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("[RSSClient] Failed to parse Atom feed %s: %w", feedURL, err)
+		}
+		return atomToFeedItems(feedURL, feed), nil
+	default:
+		return nil, fmt.Errorf("[RSSClient] Unsupported feed format %q for %s", root, feedURL)
+	}
+}
+
+// feedRoot returns the local name of the documents root element
+func feedRoot(body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("empty document")
+			}
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func rssToFeedItems(feedURL string, feed models.RSSFeed) []models.FeedItem {
+	items := make([]models.FeedItem, 0, len(feed.Channel.Items))
+	for _, item := range feed.Channel.Items {
+		description := item.Content
+		if description == "" {
+			description = item.Description
+		}
+		author := item.Creator
+		if author == "" {
+			author = item.Author
+		}
+		guid := strings.TrimSpace(item.GUID)
+		if guid == "" {
+			guid = strings.TrimSpace(item.Link)
+		}
+
+		items = append(items, models.FeedItem{
+			Feed:        feedURL,
+			GUID:        guid,
+			Title:       utils.StripHTML(item.Title),
+			Description: utils.StripHTML(description),
+			Link:        strings.TrimSpace(item.Link),
+			Author:      strings.TrimSpace(author),
+			PublishedAt: parseFeedTime(item.PubDate),
+		})
+	}
+	return items
+}
+
+func atomToFeedItems(feedURL string, feed models.AtomFeed) []models.FeedItem {
+	items := make([]models.FeedItem, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		description := entry.Content
+		if description == "" {
+			description = entry.Summary
+		}
+		link := ""
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		guid := strings.TrimSpace(entry.ID)
+		if guid == "" {
+			guid = link
+		}
+
+		items = append(items, models.FeedItem{
+			Feed:        feedURL,
+			GUID:        guid,
+			Title:       utils.StripHTML(entry.Title),
+			Description: utils.StripHTML(description),
+			Link:        link,
+			Author:      strings.TrimSpace(entry.Author.Name),
+			PublishedAt: parseFeedTime(published),
+		})
+	}
+	return items
+}
+
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -23,7 +23,10 @@ type ValkeyClient struct {
 	mu     sync.Mutex
 }
 
-const VALKEY_REDDIT_KEY = "reddit:processed_posts"
+const (
+	VALKEY_REDDIT_KEY = "reddit:processed_posts"
+	VALKEY_CURSOR_TTL = 7 * 24 * 60 * 60 // keep cursors around for a week
+)
 
 func InitValkey() *ValkeyClient {
 	valkeyOnce.Do(func() {
@@ -158,12 +161,130 @@ func (vc *ValkeyClient) IsPostProcessed(ctx context.Context, source string, key
 	return ok
 }
 
+// GetCursor returns the cursor fields stored for a source and topic, an empty
+// map means nothing has been stored yet
+func (vc *ValkeyClient) GetCursor(ctx context.Context, source string, topic string) (map[string]string, error) {
+	res := vc.DoWithRetry(ctx, vc.Client.B().Hgetall().Key(cursorKey(source, topic)).Build(), 3)
+
+	if err := res.Error(); err != nil {
+		if isConnectionError(err) {
+			vc.recreateClient()
+		}
+		return nil, err
+	}
+
+	return res.AsStrMap()
+}
+
+// SetCursor stores where a source left off for a topic so the next run can
+// resume from there
+func (vc *ValkeyClient) SetCursor(ctx context.Context, source string, topic string, fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	key := cursorKey(source, topic)
+	hset := vc.Client.B().Hset().Key(key).FieldValue()
+	for field, value := range fields {
+		hset = hset.FieldValue(field, value)
+	}
+
+	completed := []valkey.Completed{
+		hset.Build(),
+		vc.Client.B().Expire().Key(key).Seconds(VALKEY_CURSOR_TTL).Build(),
+	}
+
+	responses := vc.DoMultiWithRetry(ctx, completed, 3)
+	for _, res := range responses {
+		if err := res.Error(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetCached returns a cached value, the bool is false on a miss
+func (vc *ValkeyClient) GetCached(ctx context.Context, key string) (string, bool) {
+	res := vc.DoWithRetry(ctx, vc.Client.B().Get().Key(key).Build(), 3)
+
+	if err := res.Error(); err != nil {
+		if !valkey.IsValkeyNil(err) && isConnectionError(err) {
+			vc.recreateClient()
+		}
+		return "", false
+	}
+
+	value, err := res.ToString()
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// SetCached stores a value that expires after ttl
+func (vc *ValkeyClient) SetCached(ctx context.Context, key string, value string, ttl time.Duration) error {
+	res := vc.DoWithRetry(ctx, vc.Client.B().Set().Key(key).Value(value).Ex(ttl).Build(), 3)
+	return res.Error()
+}
+
+// GetFingerprintBuckets returns the content IDs and fingerprints stored in the
+// given LSH buckets merged into a single map
+func (vc *ValkeyClient) GetFingerprintBuckets(ctx context.Context, keys []string) (map[string]string, error) {
+	completed := make([]valkey.Completed, 0, len(keys))
+	for _, key := range keys {
+		completed = append(completed, vc.Client.B().Hgetall().Key(key).Build())
+	}
+
+	merged := make(map[string]string)
+	for _, res := range vc.DoMultiWithRetry(ctx, completed, 3) {
+		if err := res.Error(); err != nil {
+			if isConnectionError(err) {
+				vc.recreateClient()
+			}
+			return nil, err
+		}
+
+		bucket, err := res.AsStrMap()
+		if err != nil {
+			return nil, err
+		}
+		for contentID, fingerprint := range bucket {
+			merged[contentID] = fingerprint
+		}
+	}
+	return merged, nil
+}
+
+// AddToFingerprintBuckets stores a fingerprint under its content ID in every
+// bucket, buckets expire ttl after their last write
+func (vc *ValkeyClient) AddToFingerprintBuckets(ctx context.Context, keys []string, contentID string, fingerprint string, ttl time.Duration) error {
+	completed := make([]valkey.Completed, 0, 2*len(keys))
+	for _, key := range keys {
+		completed = append(completed,
+			vc.Client.B().Hset().Key(key).FieldValue().FieldValue(contentID, fingerprint).Build(),
+			vc.Client.B().Expire().Key(key).Seconds(int64(ttl.Seconds())).Build(),
+		)
+	}
+
+	for _, res := range vc.DoMultiWithRetry(ctx, completed, 3) {
+		if err := res.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cursorKey(source string, topic string) string {
+	return fmt.Sprintf("%s:cursor:%s", source, topic)
+}
+
 func keyFromSource(source string) string {
 	switch source {
 	case "reddit":
 		return VALKEY_REDDIT_KEY
 	default:
-		return ""
+		return fmt.Sprintf("%s:processed_posts", source)
 	}
 }
 
@@ -174,7 +295,7 @@ func (vc *ValkeyClient) DoMultiWithRetry(ctx context.Context, completed []valkey
 		results = vc.Client.DoMulti(ctx, completed...)
 		hasErr := false
 		for _, r := range results {
-			if r.Error() != nil {
+			if r.Error() != nil && !valkey.IsValkeyNil(r.Error()) {
 				hasErr = true
 				slog.Warn("[ValkeyClient] Do Multi failed",
 					slog.Int("attempt", i+1),
@@ -198,7 +319,8 @@ func (vc *ValkeyClient) DoWithRetry(ctx context.Context, completed valkey.Comple
 	var result valkey.ValkeyResult
 	for i := 0; i < retries; i++ {
 		result = vc.Client.Do(ctx, completed)
-		if result.Error() == nil {
+		// a nil reply is a miss, not a failure worth retrying
+		if result.Error() == nil || valkey.IsValkeyNil(result.Error()) {
 			break
 		}
 
@@ -0,0 +1,128 @@
+package consumers
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
+	"github.com/spacesedan/sentiflow/internal/langdetect"
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// What happens to content in a language the sentiment model doesn't support
+const (
+	LANGUAGE_ACTION_ROUTE = "route" // publish to the unsupported language topic
+	LANGUAGE_ACTION_DROP  = "drop"  // commit and forget
+	LANGUAGE_ACTION_KEEP  = "keep"  // tag only, analyze anyway
+)
+
+// languageRouter tags raw content with its detected language and decides
+// whether it can be analyzed
+type languageRouter struct {
+	supported     map[string]struct{}
+	minConfidence float64
+	action        string
+	topic         string
+}
+
+func newLanguageRouter() *languageRouter {
+	supported := make(map[string]struct{})
+	for _, language := range strings.Split(os.Getenv("LANGUAGE_SUPPORTED"), ",") {
+		if language = strings.TrimSpace(strings.ToLower(language)); language != "" {
+			supported[language] = struct{}{}
+		}
+	}
+	if len(supported) == 0 {
+		supported["en"] = struct{}{}
+	}
+
+	minConfidence := 0.5
+	if parsed, err := strconv.ParseFloat(os.Getenv("LANGUAGE_MIN_CONFIDENCE"), 64); err == nil {
+		minConfidence = parsed
+	}
+
+	action := os.Getenv("LANGUAGE_UNSUPPORTED_ACTION")
+	switch action {
+	case LANGUAGE_ACTION_ROUTE, LANGUAGE_ACTION_DROP, LANGUAGE_ACTION_KEEP:
+	default:
+		action = LANGUAGE_ACTION_ROUTE
+	}
+
+	topic := os.Getenv("LANGUAGE_UNSUPPORTED_TOPIC")
+	if topic == "" {
+		topic = kafka_client.KAFKA_TOPIC_RAW_CONTENT_UNSUPPORTED
+	}
+
+	return &languageRouter{
+		supported:     supported,
+		minConfidence: minConfidence,
+		action:        action,
+		topic:         topic,
+	}
+}
+
+// Tag detects the language of the content and records it in the metadata.
+// Languages declared by the source are used when detection can't tell.
+func (lr *languageRouter) Tag(content *models.RawContent) {
+	detection := langdetect.Detect(content.Text)
+	if (detection.Language == langdetect.UNDETERMINED || detection.Confidence < lr.minConfidence) &&
+		len(content.Metadata.Languages) > 0 {
+		// declared tags can carry a region (en-US), only the language is kept
+		declared, _, _ := strings.Cut(strings.ToLower(content.Metadata.Languages[0]), "-")
+		detection = langdetect.Detection{
+			Language:   declared,
+			Confidence: lr.minConfidence,
+		}
+	}
+
+	content.Metadata.Language = detection.Language
+	content.Metadata.LanguageConfidence = detection.Confidence
+}
+
+// Supported reports whether tagged content can be analyzed, content we aren't
+// confident about is given the benefit of the doubt
+func (lr *languageRouter) Supported(content models.RawContent) bool {
+	if content.Metadata.Language == langdetect.UNDETERMINED ||
+		content.Metadata.LanguageConfidence < lr.minConfidence {
+		return true
+	}
+	_, ok := lr.supported[content.Metadata.Language]
+	return ok
+}
+
+// Divert handles content in an unsupported language according to the
+// configured action, it reports false when the content should be analyzed
+// anyway. Content that could not be routed is analyzed rather than lost.
+func (lr *languageRouter) Divert(ctx context.Context, content models.RawContent) bool {
+	switch lr.action {
+	case LANGUAGE_ACTION_KEEP:
+		return false
+
+	case LANGUAGE_ACTION_DROP:
+		slog.Debug("[RawContentConsumer] Dropping unsupported language",
+			slog.String("content_id", content.ContentID),
+			slog.String("language", content.Metadata.Language))
+		return true
+	}
+
+	for i := 0; i < 3; i++ {
+		err := kafka_client.PublishToKafka(ctx, lr.topic, content)
+		if err == nil {
+			return true
+		}
+		slog.Warn("[RawContentConsumer] Unsupported language publishing failed",
+			slog.Int("attempt", i+1),
+			slog.String("language", content.Metadata.Language),
+			slog.String("error", err.Error()))
+		time.Sleep(2 * time.Second)
+	}
+
+	slog.Error("[RawContentConsumer] Failed to route unsupported language, analyzing it instead",
+		slog.String("content_id", content.ContentID),
+		slog.String("language", content.Metadata.Language))
+	return false
+}
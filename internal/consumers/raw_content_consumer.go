@@ -19,6 +19,9 @@ func StartRawContentConsumer(ctx context.Context, consumer *kafka.Consumer) {
 	iterator := kafka_client.NewKafkaMessageIterator(ctx, consumer)
 	committer := kafka_client.NewCommitHandler(ctx, consumer)
 
+	languages := newLanguageRouter()
+	redactor := newInputRedactor()
+
 	slog.Info("[RawContentConsumer] Listening for messages...")
 
 	ticker := time.NewTicker(utils.BATCH_TIMEOUT)
@@ -44,8 +47,29 @@ func StartRawContentConsumer(ctx context.Context, consumer *kafka.Consumer) {
 				continue
 			}
 
-			// convert the raw content to a sentiment analysis input
-			saInput := utils.RawToSentimentAnalysisInput(content)
+			// the sentiment model only handles some languages, the rest is
+			// routed elsewhere or dropped before it gets scored as garbage
+			languages.Tag(&content)
+			if !languages.Supported(content) && languages.Divert(ctx, content) {
+				if err := committer.Commit(msg); err != nil {
+					slog.Warn("[RawContentConsumer] Failed to commit offset",
+						slog.String("error", err.Error()))
+				}
+				continue
+			}
+
+			// convert the raw content to a sentiment analysis input, personal
+			// information is redacted before the text leaves for the models
+			saInput := redactor.Redact(utils.RawToSentimentAnalysisInput(content))
+
+			// posts that were only a link, a quote or code have nothing left to analyze
+			if saInput.Text == "" {
+				if err := committer.Commit(msg); err != nil {
+					slog.Warn("[RawContentConsumer] Failed to commit offset",
+						slog.String("error", err.Error()))
+				}
+				continue
+			}
 
 			// track the message for downstream use
 			utils.TrackMessage(saInput.ContentID, msg)
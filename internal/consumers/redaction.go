@@ -0,0 +1,51 @@
+package consumers
+
+import (
+	"os"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/redact"
+)
+
+// inputRedactor strips personal information from analysis inputs before they
+// are sent to the summarizer and analyzer
+type inputRedactor struct {
+	redactor *redact.Redactor
+	// persistUnredacted keeps the raw text in OriginalText, set per
+	// environment with REDACT_PERSIST_UNREDACTED
+	persistUnredacted bool
+}
+
+func newInputRedactor() *inputRedactor {
+	return &inputRedactor{
+		redactor:          redact.NewRedactor(),
+		persistUnredacted: os.Getenv("REDACT_PERSIST_UNREDACTED") == "true",
+	}
+}
+
+// Redact redacts the text sent to the models and records how much was
+// redacted, the original text is redacted too unless unredacted text may be persisted
+func (ir *inputRedactor) Redact(input models.SentimentAnalysisInput) models.SentimentAnalysisInput {
+	text, counts := ir.redactor.Redact(input.Text)
+	input.Text = text
+
+	if article := input.Metadata.LinkedArticle; article != nil {
+		redacted := *article
+		var articleCounts redact.Counts
+		redacted.Text, articleCounts = ir.redactor.Redact(article.Text)
+		for name, count := range articleCounts {
+			counts[name] += count
+		}
+		input.Metadata.LinkedArticle = &redacted
+	}
+
+	if !ir.persistUnredacted {
+		input.OriginalText, _ = ir.redactor.Redact(input.OriginalText)
+		input.RawContent.Text, _ = ir.redactor.Redact(input.RawContent.Text)
+	}
+
+	if len(counts) > 0 {
+		input.Redactions = counts
+	}
+	return input
+}
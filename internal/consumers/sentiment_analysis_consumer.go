@@ -3,6 +3,7 @@ package consumers
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -47,6 +48,15 @@ func StartSentimentAnalysisConsumer(ctx context.Context, consumer *kafka.Consume
 					ContentID: request.ContentID,
 					Text:      request.Text,
 				})
+
+				// Linked articles are scored as their own entries so the article's
+				// tone does not bleed into the score of the post
+				if article := request.Metadata.LinkedArticle; article != nil && article.Text != "" {
+					hfRequest.Posts = append(hfRequest.Posts, models.SentimentAnalysisRequest{
+						ContentID: linkedArticleContentID(request.ContentID),
+						Text:      articleLead(article.Text),
+					})
+				}
 			}
 
 			if len(healthy) > 0 && healthy[0] != nil && !healthy[0].Load() {
@@ -69,6 +79,16 @@ func StartSentimentAnalysisConsumer(ctx context.Context, consumer *kafka.Consume
 					slog.Warn("[SentimentAnalysisConsumer] No sentiment results for content ID",
 						slog.String("content_id", request.ContentID))
 				}
+				if article := request.Metadata.LinkedArticle; article != nil {
+					scored := *article
+					if articleScore, ok := mappedScores[linkedArticleContentID(request.ContentID)]; ok {
+						scored.SentimentScore = articleScore.SentimentScore
+						scored.SentimentLabel = articleScore.SentimentLabel
+						scored.Confidence = articleScore.Confidence
+					}
+					request.Metadata.LinkedArticle = &scored
+				}
+
 				resultBuffer.Add(models.SentimentAnalysisResult{
 					SentimentAnalysisInput: request,
 					SentimentScore:         score.SentimentScore,
@@ -113,6 +133,25 @@ func sendResultsForStorage(ctx context.Context, committer *kafka_client.KafkaCom
 	}
 }
 
+// linkedArticleContentID is the ID the linked article of a post is scored under
+func linkedArticleContentID(contentID string) string {
+	return contentID + ":article"
+}
+
+// articleLead trims an article to the part that fits in a single analysis
+// request, the opening paragraphs carry most of an article's framing
+func articleLead(text string) string {
+	if len(text) <= SUMMARY_THRESHOLD {
+		return text
+	}
+
+	lead := text[:SUMMARY_THRESHOLD]
+	if i := strings.LastIndexAny(lead, ".!?\n"); i > SUMMARY_THRESHOLD/2 {
+		lead = lead[:i+1]
+	}
+	return strings.ToValidUTF8(lead, "")
+}
+
 // mapSentimentScoreToContentID Creates a map to sentiment scores to avoid nested loops
 func mapSentimentScoreToContentID(scores models.SentimentAnalysisBatchResponse) map[string]models.SentimentAnalysisResponse {
 	scoreMap := make(map[string]models.SentimentAnalysisResponse, len(scores))
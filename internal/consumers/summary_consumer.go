@@ -183,6 +183,12 @@ func sendForAnalysis(ctx context.Context, commiter *kafka_client.KafkaCommitHand
 
 // buildSummarizedSentimentInput Builds a new sentiment analysis request using summarized text
 func buildSummarizedSentimentInput(request models.SentimentAnalysisInput, summary string) models.SentimentAnalysisInput {
+	// keep the raw text when the input was already normalized
+	originalText := request.OriginalText
+	if originalText == "" {
+		originalText = request.Text
+	}
+
 	return models.SentimentAnalysisInput{
 		RawContent: models.RawContent{
 			ContentID: request.ContentID,
@@ -191,7 +197,8 @@ func buildSummarizedSentimentInput(request models.SentimentAnalysisInput, summar
 			Metadata:  request.Metadata,
 		},
 		Text:          summary,
-		OriginalText:  request.Text,
+		OriginalText:  originalText,
 		WasSummarized: true,
+		Redactions:    request.Redactions,
 	}
 }
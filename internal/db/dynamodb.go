@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -48,15 +49,29 @@ func StoreBatchedTopics(ctx context.Context, topics []models.Topic) error {
 
 			writeRequests := make([]types.WriteRequest, 0, maxBatchSize)
 			for _, topic := range topics[i:end] {
+				item := map[string]types.AttributeValue{
+					"url":        &types.AttributeValueMemberS{Value: topic.URL},
+					"category":   &types.AttributeValueMemberS{Value: topic.Category},
+					"topic":      &types.AttributeValueMemberS{Value: topic.Topic},
+					"title":      &types.AttributeValueMemberS{Value: topic.Title},
+					"expires_at": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expirationTime)},
+				}
+				if topic.Country != "" {
+					item["country"] = &types.AttributeValueMemberS{Value: topic.Country}
+				}
+				if topic.Language != "" {
+					item["language"] = &types.AttributeValueMemberS{Value: topic.Language}
+				}
+				if topic.Query != "" {
+					item["query"] = &types.AttributeValueMemberS{Value: topic.Query}
+				}
+				if len(topic.SourceURLs) > 0 {
+					item["source_urls"] = &types.AttributeValueMemberSS{Value: topic.SourceURLs}
+				}
+
 				writeRequests = append(writeRequests, types.WriteRequest{
 					PutRequest: &types.PutRequest{
-						Item: map[string]types.AttributeValue{
-							"url":        &types.AttributeValueMemberS{Value: topic.URL},
-							"category":   &types.AttributeValueMemberS{Value: topic.Category},
-							"topic":      &types.AttributeValueMemberS{Value: topic.Topic},
-							"title":      &types.AttributeValueMemberS{Value: topic.Title},
-							"expires_at": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expirationTime)},
-						},
+						Item: item,
 					},
 				})
 			}
@@ -102,6 +117,40 @@ func StoreBatchedTopics(ctx context.Context, topics []models.Topic) error {
 	return nil
 }
 
+// AddTopicSourceURLs adds the URLs of duplicate headlines to a stored topic,
+// topics that expired in the meantime are left alone
+func AddTopicSourceURLs(ctx context.Context, topicURL string, sourceURLs []string) error {
+	if dbClient == nil {
+		dbClient = clients.GetDynamoDBClient()
+	}
+	if len(sourceURLs) == 0 {
+		return nil
+	}
+
+	_, err := dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(TOPICS_TABLE_NAME),
+		Key: map[string]types.AttributeValue{
+			"url": &types.AttributeValueMemberS{Value: topicURL},
+		},
+		UpdateExpression:    aws.String("ADD source_urls :urls"),
+		ConditionExpression: aws.String("attribute_exists(#url)"),
+		ExpressionAttributeNames: map[string]string{
+			"#url": "url",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":urls": &types.AttributeValueMemberSS{Value: sourceURLs},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return nil
+		}
+		return fmt.Errorf("[DynamoDB] Failed to add source URLs to topic: %w", err)
+	}
+	return nil
+}
+
 func GetAllTopics() ([]models.Topic, error) {
 	if dbClient == nil {
 		dbClient = clients.GetDynamoDBClient()
@@ -188,6 +237,15 @@ func BatchInsertSentimentResults(ctx context.Context, results []models.Sentiment
 	return nil
 }
 
+// resultWeight is how much a result counts in topic aggregates, originals
+// count fully and near-duplicates for the weight they were published with
+func resultWeight(result models.SentimentAnalysisResult) float64 {
+	if result.Metadata.DuplicateOf == "" {
+		return 1
+	}
+	return result.Metadata.Weight
+}
+
 func ResultToDynamoDBItem(result models.SentimentAnalysisResult) map[string]types.AttributeValue {
 	item := make(map[string]types.AttributeValue)
 
@@ -197,6 +255,7 @@ func ResultToDynamoDBItem(result models.SentimentAnalysisResult) map[string]type
 	item["sentiment_score"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", result.SentimentScore)}
 	item["sentiment_label"] = &types.AttributeValueMemberS{Value: result.SentimentLabel}
 	item["confidence"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", result.Confidence)}
+	item["weight"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", resultWeight(result))}
 	item["created_at"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().Unix())}
 	item["ttl"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().Add(24*time.Hour).Unix())}
 
@@ -205,6 +264,9 @@ func ResultToDynamoDBItem(result models.SentimentAnalysisResult) map[string]type
 	if result.Metadata.Author != "" {
 		metadata["author"] = &types.AttributeValueMemberS{Value: result.Metadata.Author}
 	}
+	if result.Metadata.AuthorID != "" {
+		metadata["author_id"] = &types.AttributeValueMemberS{Value: result.Metadata.AuthorID}
+	}
 	if result.Metadata.PostID != "" {
 		metadata["post_id"] = &types.AttributeValueMemberS{Value: result.Metadata.PostID}
 	}
@@ -214,6 +276,68 @@ func ResultToDynamoDBItem(result models.SentimentAnalysisResult) map[string]type
 	if result.Metadata.Subreddit != "" {
 		metadata["subreddit"] = &types.AttributeValueMemberS{Value: result.Metadata.Subreddit}
 	}
+	if result.Metadata.ParentPostID != "" {
+		metadata["parent_post_id"] = &types.AttributeValueMemberS{Value: result.Metadata.ParentPostID}
+	}
+	if result.Metadata.Permalink != "" {
+		metadata["permalink"] = &types.AttributeValueMemberS{Value: result.Metadata.Permalink}
+	}
+	if result.Metadata.Instance != "" {
+		metadata["instance"] = &types.AttributeValueMemberS{Value: result.Metadata.Instance}
+	}
+	if result.Metadata.DuplicateOf != "" {
+		metadata["duplicate_of"] = &types.AttributeValueMemberS{Value: result.Metadata.DuplicateOf}
+	}
+	if result.Metadata.Language != "" {
+		metadata["language"] = &types.AttributeValueMemberS{Value: result.Metadata.Language}
+		metadata["language_confidence"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", result.Metadata.LanguageConfidence)}
+	}
+	if result.Metadata.Upvotes != 0 {
+		metadata["upvotes"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", result.Metadata.Upvotes)}
+	}
+	if result.Metadata.UpvoteRatio != 0 {
+		metadata["upvote_ratio"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", result.Metadata.UpvoteRatio)}
+	}
+	if result.Metadata.NumComments != 0 {
+		metadata["num_comments"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", result.Metadata.NumComments)}
+	}
+	if result.Metadata.LinkFlairText != "" {
+		metadata["link_flair_text"] = &types.AttributeValueMemberS{Value: result.Metadata.LinkFlairText}
+	}
+	if result.Metadata.Over18 {
+		metadata["over_18"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+	if result.Metadata.Stickied {
+		metadata["stickied"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+	if article := result.Metadata.LinkedArticle; article != nil {
+		// the article text is left out to keep items small, only its tone is stored
+		linked := map[string]types.AttributeValue{
+			"url":             &types.AttributeValueMemberS{Value: article.URL},
+			"sentiment_score": &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", article.SentimentScore)},
+			"confidence":      &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", article.Confidence)},
+		}
+		if article.Title != "" {
+			linked["title"] = &types.AttributeValueMemberS{Value: article.Title}
+		}
+		if article.SentimentLabel != "" {
+			linked["sentiment_label"] = &types.AttributeValueMemberS{Value: article.SentimentLabel}
+		}
+		metadata["linked_article"] = &types.AttributeValueMemberM{Value: linked}
+	}
+	if result.Metadata.Publisher != "" {
+		metadata["publisher"] = &types.AttributeValueMemberS{Value: result.Metadata.Publisher}
+	}
+	if result.Metadata.TextComposition != "" {
+		metadata["text_composition"] = &types.AttributeValueMemberS{Value: result.Metadata.TextComposition}
+	}
+	if len(result.Metadata.Languages) > 0 {
+		languages := make([]types.AttributeValue, 0, len(result.Metadata.Languages))
+		for _, language := range result.Metadata.Languages {
+			languages = append(languages, &types.AttributeValueMemberS{Value: language})
+		}
+		metadata["languages"] = &types.AttributeValueMemberL{Value: languages}
+	}
 	if !result.Metadata.Timestamp.IsZero() {
 		metadata["timestamp"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", result.Metadata.Timestamp.Unix())}
 	}
@@ -225,6 +349,13 @@ func ResultToDynamoDBItem(result models.SentimentAnalysisResult) map[string]type
 	if result.Text != "" {
 		item["text"] = &types.AttributeValueMemberS{Value: result.Text}
 	}
+	if len(result.Redactions) > 0 {
+		redactions := make(map[string]types.AttributeValue, len(result.Redactions))
+		for detector, count := range result.Redactions {
+			redactions[detector] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", count)}
+		}
+		item["redactions"] = &types.AttributeValueMemberM{Value: redactions}
+	}
 	if result.OriginalText != "" {
 		item["original_text"] = &types.AttributeValueMemberS{Value: result.OriginalText}
 	}
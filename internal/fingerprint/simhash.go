@@ -0,0 +1,82 @@
+// Package fingerprint computes SimHash fingerprints of text so near-duplicates
+// can be found by Hamming distance, and splits them into bands for LSH lookups.
+package fingerprint
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// fingerprints are split into this many bands of BAND_BITS, two
+	// fingerprints within BANDS-1 bits of each other always share a band
+	BANDS     = 8
+	BAND_BITS = 64 / BANDS
+)
+
+var urls = regexp.MustCompile(`https?://\S+|www\.\S+`)
+
+// Normalize lowercases text and reduces it to its words, so formatting, links
+// and punctuation don't change the fingerprint
+func Normalize(text string) []string {
+	text = urls.ReplaceAllString(strings.ToLower(text), " ")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// SimHash fingerprints a list of words, the features are the words and every
+// pair of adjacent words. Social media texts are short, single words keep a
+// prefix like "BREAKING:" from moving the fingerprint too far while the pairs
+// still tell apart texts that reuse the same vocabulary.
+func SimHash(words []string) uint64 {
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle string) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	for i, word := range words {
+		add(word)
+		if i > 0 {
+			add(words[i-1] + " " + word)
+		}
+	}
+
+	var fingerprint uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// Distance is the number of bits two fingerprints differ in
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Bands splits a fingerprint into its LSH bands
+func Bands(fingerprint uint64) [BANDS]uint8 {
+	var bands [BANDS]uint8
+	for i := 0; i < BANDS; i++ {
+		bands[i] = uint8(fingerprint >> (BAND_BITS * i))
+	}
+	return bands
+}
@@ -0,0 +1,85 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+)
+
+const DEFAULT_KEY_RATE_LIMIT = 600 // records per minute
+
+// APIKey is a partner credential with its own rate limit, charged per record
+// so a batch costs as much as sending its records one by one
+type APIKey struct {
+	Name    string
+	hash    [32]byte
+	limiter *clients.RateLimiter
+}
+
+// Source is the source content ingested with the key is published under
+func (k *APIKey) Source() string {
+	return "partner:" + k.Name
+}
+
+// ParseAPIKeys reads keys in the form name:key[:records_per_minute], comma separated
+func ParseAPIKeys(value string) ([]*APIKey, error) {
+	var keys []*APIKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("[Ingest] Invalid API key entry, expected name:key[:records_per_minute]")
+		}
+
+		rate := DEFAULT_KEY_RATE_LIMIT
+		if len(parts) == 3 {
+			parsed, err := strconv.Atoi(parts[2])
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("[Ingest] Invalid rate limit for API key %q", parts[0])
+			}
+			rate = parsed
+		}
+
+		keys = append(keys, &APIKey{
+			Name:    parts[0],
+			hash:    sha256.Sum256([]byte(parts[1])),
+			limiter: clients.NewRateLimiter(rate, rate, time.Minute),
+		})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("[Ingest] No API keys configured")
+	}
+	return keys, nil
+}
+
+// authenticate matches the key sent with the request, comparing hashes in
+// constant time so the keys can't be guessed byte by byte
+func (s *Server) authenticate(r *http.Request) *APIKey {
+	provided := r.Header.Get("X-API-Key")
+	if provided == "" {
+		provided, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if provided == "" {
+		return nil
+	}
+
+	hash := sha256.Sum256([]byte(provided))
+	var match *APIKey
+	for _, key := range s.keys {
+		if subtle.ConstantTimeCompare(hash[:], key.hash[:]) == 1 {
+			match = key
+		}
+	}
+	return match
+}
@@ -0,0 +1,224 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/producer"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+)
+
+const (
+	MAX_BODY_SIZE  = 5 * 1024 * 1024
+	MAX_BATCH_SIZE = 500
+
+	// seconds partners are told to wait before retrying a failed publish
+	PUBLISH_RETRY_AFTER = "30"
+)
+
+// errInvalidContent marks records rejected by validation, anything else that
+// fails a record is a publish failure worth retrying
+var errInvalidContent = errors.New("invalid content")
+
+// Server accepts RawContent from partner teams and publishes it to raw-content
+type Server struct {
+	keys []*APIKey
+}
+
+type IngestResponse struct {
+	Accepted   int              `json:"accepted"`
+	Duplicates int              `json:"duplicates"`
+	Records    []IngestedRecord `json:"records,omitempty"`
+	Rejected   []RejectedRecord `json:"rejected,omitempty"`
+}
+
+// IngestedRecord is the content ID the server computed for an accepted or
+// duplicate record, partners never choose it themselves
+type IngestedRecord struct {
+	Index     int    `json:"index"`
+	ContentID string `json:"content_id"`
+}
+
+// RejectedRecord is a record that wasn't published, retryable records failed
+// on our side and can be sent again as is
+type RejectedRecord struct {
+	Index     int    `json:"index"`
+	Error     string `json:"error"`
+	Retryable bool   `json:"retryable,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewServer(keys []*APIKey) *Server {
+	return &Server{keys: keys}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /v1/content", s.handleContent)
+	return mux
+}
+
+// handleContent publishes a single RawContent object or an array of them. The
+// source is always the key's own and the content ID is computed by the server
+// from it, the topic and the partner's ID for the record, sent as either
+// content_id or metadata.post_id. The computed IDs are returned in records.
+func (s *Server) handleContent(w http.ResponseWriter, r *http.Request) {
+	key := s.authenticate(r)
+	if key == nil {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing or invalid API key"})
+		return
+	}
+
+	contents, err := decodeContents(http.MaxBytesReader(w, r.Body, MAX_BODY_SIZE))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	// the limit is charged per record, a batch larger than the key's whole
+	// budget could never be accepted so it is refused outright
+	if len(contents) > key.limiter.Capacity() {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{
+			Error: fmt.Sprintf("batch exceeds the key's limit of %d records per minute", key.limiter.Capacity()),
+		})
+		return
+	}
+	if !key.limiter.AllowN(len(contents)) {
+		w.Header().Set("Retry-After", "1")
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "rate limit exceeded"})
+		return
+	}
+
+	start := time.Now()
+	response := IngestResponse{}
+	var publishErr error
+	for i := range contents {
+		// once publishing fails the rest of the batch would only fail the
+		// same way, so it is handed back to be retried
+		if publishErr != nil {
+			response.Rejected = append(response.Rejected, RejectedRecord{
+				Index:     i,
+				Error:     "not published after an earlier publish failure",
+				Retryable: true,
+			})
+			continue
+		}
+
+		if err := s.ingest(r.Context(), key, &contents[i]); err != nil {
+			switch {
+			case errors.Is(err, rawcontent.ErrDuplicateContent):
+				response.Duplicates++
+				response.Records = append(response.Records, IngestedRecord{Index: i, ContentID: contents[i].ContentID})
+			case errors.Is(err, errInvalidContent):
+				response.Rejected = append(response.Rejected, RejectedRecord{Index: i, Error: err.Error()})
+			default:
+				publishErr = err
+				response.Rejected = append(response.Rejected, RejectedRecord{Index: i, Error: "failed to publish", Retryable: true})
+			}
+			continue
+		}
+		response.Accepted++
+		response.Records = append(response.Records, IngestedRecord{Index: i, ContentID: contents[i].ContentID})
+	}
+
+	slog.Info("[Ingest] Processed request",
+		slog.String("key", key.Name),
+		slog.Int("records", len(contents)),
+		slog.Int("accepted", response.Accepted),
+		slog.Int("duplicates", response.Duplicates),
+		slog.Int("rejected", len(response.Rejected)),
+		slog.Duration("elapsed", time.Since(start)))
+
+	status := http.StatusAccepted
+	switch {
+	case publishErr != nil:
+		// published records are deduped, so retrying the whole batch is safe
+		slog.Error("[Ingest] Failed to publish content",
+			slog.String("key", key.Name),
+			slog.String("error", publishErr.Error()))
+		w.Header().Set("Retry-After", PUBLISH_RETRY_AFTER)
+		status = http.StatusServiceUnavailable
+	case response.Accepted == 0 && response.Duplicates == 0:
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, response)
+}
+
+// ingest publishes a single record under the key's own source. The source and
+// content ID are never taken from the partner, so a key can't write into
+// another source's dedupe set or collide with its content IDs. A content_id
+// sent by the partner is their ID for the record and is kept as the post ID.
+func (s *Server) ingest(ctx context.Context, key *APIKey, content *models.RawContent) error {
+	content.Source = key.Source()
+	if content.ContentID != "" && content.Metadata.PostID != "" {
+		return fmt.Errorf("%w: send either content_id or metadata.post_id, not both", errInvalidContent)
+	}
+	if content.Metadata.PostID == "" {
+		content.Metadata.PostID = content.ContentID
+	}
+	content.ContentID = ""
+
+	if err := producer.PrepareRawContent(content); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidContent, err)
+	}
+	if content.Metadata.Timestamp.IsZero() {
+		content.Metadata.Timestamp = time.Now()
+	}
+	return rawcontent.Publish(ctx, *content)
+}
+
+// decodeContents accepts either a single RawContent object or an array of them
+func decodeContents(body io.Reader) ([]models.RawContent, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("request body is empty")
+	}
+
+	var contents []models.RawContent
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &contents); err != nil {
+			return nil, errors.New("invalid JSON array of content")
+		}
+	} else {
+		var content models.RawContent
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, errors.New("invalid JSON content object")
+		}
+		contents = append(contents, content)
+	}
+
+	if len(contents) == 0 {
+		return nil, errors.New("no content in request")
+	}
+	if len(contents) > MAX_BATCH_SIZE {
+		return nil, errors.New("batch exceeds the maximum of 500 records")
+	}
+	return contents, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Warn("[Ingest] Failed to write response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,261 @@
+// Package langdetect identifies the language of short social media texts
+// without calling out to a service. Texts in a script used by a single
+// language are identified by script alone, Latin and Cyrillic texts are
+// compared against character trigram profiles built from the embedded samples.
+package langdetect
+
+import (
+	"embed"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// UNDETERMINED is reported when a text is too short or has too few letters to tell
+const UNDETERMINED = "und"
+
+const (
+	// texts with fewer letters than this are not worth guessing at, ideographs
+	// carry a word each and count as IDEOGRAPH_WEIGHT letters
+	MIN_LETTERS      = 12
+	IDEOGRAPH_WEIGHT = 3
+	// number of trigrams kept per language profile
+	PROFILE_SIZE = 400
+	// lead over the runner up profile at which a detection is fully confident
+	MARGIN_FOR_CERTAINTY = 0.25
+)
+
+// Detection is the detected language as an ISO 639-1 code with a confidence
+// between 0 and 1
+type Detection struct {
+	Language   string
+	Confidence float64
+}
+
+//go:embed samples/*.txt
+var samples embed.FS
+
+var (
+	profilesOnce sync.Once
+	profiles     map[*unicode.RangeTable]map[string]profile
+
+	urlsAndMentions = regexp.MustCompile(`https?://\S+|www\.\S+|[@#]\w+|/?[ur]/\w+`)
+)
+
+// profile maps a trigram to its rank in the language sample
+type profile map[string]int
+
+// Scripts that are only written in one of the languages we care about
+var singleLanguageScripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+}
+
+// Detect returns the most likely language of a text
+func Detect(text string) Detection {
+	text = urlsAndMentions.ReplaceAllString(text, " ")
+
+	counts, letters := countScripts(text)
+	weighted := letters + (IDEOGRAPH_WEIGHT-1)*(counts[unicode.Han]+counts[unicode.Hangul])
+	if weighted < MIN_LETTERS {
+		return Detection{Language: UNDETERMINED}
+	}
+
+	// Japanese mixes kana with Han, any kana at all decides it
+	if counts[unicode.Hiragana]+counts[unicode.Katakana] > 0 && counts[unicode.Han] > 0 {
+		share := float64(counts[unicode.Hiragana]+counts[unicode.Katakana]+counts[unicode.Han]) / float64(letters)
+		return Detection{Language: "ja", Confidence: share}
+	}
+
+	dominant := dominantScript(counts)
+	share := float64(counts[dominant]) / float64(letters)
+
+	for _, single := range singleLanguageScripts {
+		if single.table == dominant {
+			return Detection{Language: single.language, Confidence: share}
+		}
+	}
+
+	profilesOnce.Do(loadProfiles)
+	candidates, ok := profiles[dominant]
+	if !ok {
+		return Detection{Language: UNDETERMINED}
+	}
+
+	language, confidence := closestProfile(trigrams(text), candidates)
+	return Detection{Language: language, Confidence: confidence * share}
+}
+
+// Scripts letters are counted under, checked in order
+var scriptTables = []*unicode.RangeTable{
+	unicode.Latin,
+	unicode.Cyrillic,
+	unicode.Hangul,
+	unicode.Hiragana,
+	unicode.Katakana,
+	unicode.Han,
+	unicode.Arabic,
+	unicode.Devanagari,
+	unicode.Greek,
+	unicode.Hebrew,
+	unicode.Thai,
+}
+
+// closestProfile scores the text against every profile with the out-of-place
+// measure, confidence is how far ahead the best profile is of the runner up
+func closestProfile(ranked []string, candidates map[string]profile) (string, float64) {
+	if len(ranked) == 0 {
+		return UNDETERMINED, 0
+	}
+
+	maxDistance := float64(len(ranked) * PROFILE_SIZE)
+	type score struct {
+		language   string
+		similarity float64
+	}
+	scores := make([]score, 0, len(candidates))
+
+	for language, p := range candidates {
+		distance := 0
+		for i, gram := range ranked {
+			rank, ok := p[gram]
+			if !ok {
+				distance += PROFILE_SIZE
+				continue
+			}
+			distance += min(abs(rank-i), PROFILE_SIZE)
+		}
+		scores = append(scores, score{language, 1 - float64(distance)/maxDistance})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].similarity == scores[j].similarity {
+			return scores[i].language < scores[j].language
+		}
+		return scores[i].similarity > scores[j].similarity
+	})
+
+	best := scores[0]
+	if best.similarity <= 0 {
+		return UNDETERMINED, 0
+	}
+	if len(scores) == 1 {
+		return best.language, best.similarity
+	}
+
+	// a relative margin of MARGIN_FOR_CERTAINTY or more is treated as certain
+	margin := (best.similarity - scores[1].similarity) / best.similarity
+	return best.language, math.Min(margin/MARGIN_FOR_CERTAINTY, 1)
+}
+
+// trigrams returns the texts character trigrams ordered by frequency, words
+// are padded with spaces so prefixes and suffixes get their own trigrams
+func trigrams(text string) []string {
+	freq := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		runes := []rune(" " + strings.Trim(word, "'") + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			freq[string(runes[i:i+3])]++
+		}
+	}
+
+	grams := make([]string, 0, len(freq))
+	for gram := range freq {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if freq[grams[i]] == freq[grams[j]] {
+			return grams[i] < grams[j]
+		}
+		return freq[grams[i]] > freq[grams[j]]
+	})
+
+	if len(grams) > PROFILE_SIZE {
+		grams = grams[:PROFILE_SIZE]
+	}
+	return grams
+}
+
+// loadProfiles builds a profile for every embedded sample, grouped by the
+// script the sample is written in
+func loadProfiles() {
+	profiles = make(map[*unicode.RangeTable]map[string]profile)
+
+	entries, err := samples.ReadDir("samples")
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		data, err := samples.ReadFile(path.Join("samples", entry.Name()))
+		if err != nil {
+			continue
+		}
+		language := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+
+		p := make(profile)
+		for rank, gram := range trigrams(string(data)) {
+			p[gram] = rank
+		}
+
+		counts, _ := countScripts(string(data))
+		script := dominantScript(counts)
+		if profiles[script] == nil {
+			profiles[script] = make(map[string]profile)
+		}
+		profiles[script][language] = p
+	}
+}
+
+// countScripts counts the letters of a text per script
+func countScripts(text string) (map[*unicode.RangeTable]int, int) {
+	letters := 0
+	counts := make(map[*unicode.RangeTable]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scriptTables {
+			if unicode.Is(script, r) {
+				counts[script]++
+				break
+			}
+		}
+	}
+	return counts, letters
+}
+
+// dominantScript is the script most letters are written in, Latin on a tie
+func dominantScript(counts map[*unicode.RangeTable]int) *unicode.RangeTable {
+	dominant, dominantCount := unicode.Latin, 0
+	for _, script := range scriptTables {
+		if counts[script] > dominantCount {
+			dominant, dominantCount = script, counts[script]
+		}
+	}
+	return dominant
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
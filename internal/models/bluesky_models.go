@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type BlueskySearchPostsResponse struct {
+	Cursor    string        `json:"cursor"`
+	HitsTotal int           `json:"hitsTotal"`
+	Posts     []BlueskyPost `json:"posts"`
+}
+
+type BlueskyPost struct {
+	URI         string            `json:"uri"`
+	CID         string            `json:"cid"`
+	Author      BlueskyAuthor     `json:"author"`
+	Record      BlueskyPostRecord `json:"record"`
+	IndexedAt   time.Time         `json:"indexedAt"`
+	LikeCount   int               `json:"likeCount"`
+	ReplyCount  int               `json:"replyCount"`
+	RepostCount int               `json:"repostCount"`
+}
+
+type BlueskyAuthor struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"displayName"`
+}
+
+type BlueskyPostRecord struct {
+	Text string `json:"text"`
+	// CreatedAt is set by the posting client and often malformed, it is kept
+	// as a string so one bad record can't fail the whole page
+	CreatedAt string   `json:"createdAt"`
+	Langs     []string `json:"langs"`
+}
@@ -0,0 +1,23 @@
+package models
+
+type HNSearchResponse struct {
+	Hits        []HNHit `json:"hits"`
+	Page        int     `json:"page"`
+	NbPages     int     `json:"nbPages"`
+	HitsPerPage int     `json:"hitsPerPage"`
+}
+
+type HNHit struct {
+	ObjectID    string   `json:"objectID"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Author      string   `json:"author"`
+	StoryText   string   `json:"story_text"`
+	CommentText string   `json:"comment_text"`
+	StoryID     int      `json:"story_id"`
+	StoryTitle  string   `json:"story_title"`
+	Points      int      `json:"points"`
+	NumComments int      `json:"num_comments"`
+	CreatedAtI  int64    `json:"created_at_i"`
+	Tags        []string `json:"_tags"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type MastodonSearchResponse struct {
+	Statuses []MastodonStatus `json:"statuses"`
+}
+
+type MastodonStatus struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Content   string          `json:"content"`
+	URL       string          `json:"url"`
+	URI       string          `json:"uri"`
+	Language  string          `json:"language"`
+	Sensitive bool            `json:"sensitive"`
+	Account   MastodonAccount `json:"account"`
+	Reblog    *MastodonStatus `json:"reblog"`
+}
+
+type MastodonAccount struct {
+	Acct     string `json:"acct"`
+	Username string `json:"username"`
+	URL      string `json:"url"`
+	Bot      bool   `json:"bot"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type NewsAPITopHeadlinesResponse = struct {
 	Status       string            `json:"status"`
 	TotalResults int               `json:"totalResults"`
@@ -7,6 +9,38 @@ type NewsAPITopHeadlinesResponse = struct {
 }
 
 type NewsAPIArticles = struct {
+	Source      NewsAPISource `json:"source"`
+	Author      string        `json:"author"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	Content     string        `json:"content"`
+	PublishedAt time.Time     `json:"publishedAt"`
+	Origin      NewsAPIOrigin `json:"-"`
+}
+
+type NewsAPISource = struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewsAPIHeadline is the part of an article sent to the model for topic generation
+type NewsAPIHeadline struct {
 	Title string `json:"title"`
 	URL   string `json:"url"`
 }
+
+// NewsAPIOrigin records the request an article was returned for
+type NewsAPIOrigin struct {
+	Endpoint string
+	Category string
+	Country  string
+	Language string
+	Query    string
+}
+
+type NewsAPIError struct {
+	Status  string `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -11,9 +11,45 @@ type RawContent struct {
 }
 
 type ContentMetadata struct {
-	Timestamp time.Time `json:"timestamp"`
-	Author    string    `json:"author"`
-	Subreddit string    `json:"subreddit,omitempty"`
-	PostID    string    `json:"post_id,omitempty"`
-	URL       string    `json:"url,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+	Author          string    `json:"author"`
+	AuthorID        string    `json:"author_id,omitempty"`
+	Subreddit       string    `json:"subreddit,omitempty"`
+	PostID          string    `json:"post_id,omitempty"`
+	URL             string    `json:"url,omitempty"`
+	ParentPostID    string    `json:"parent_post_id,omitempty"`
+	Permalink       string    `json:"permalink,omitempty"`
+	Instance        string    `json:"instance,omitempty"`
+	Publisher       string    `json:"publisher,omitempty"`
+	TextComposition string    `json:"text_composition,omitempty"`
+	Languages       []string  `json:"languages,omitempty"`
+
+	// Detected in the raw-content stage, Languages above are declared by the source
+	Language           string  `json:"language,omitempty"`
+	LanguageConfidence float64 `json:"language_confidence,omitempty"`
+
+	// Engagement and flair, currently only set for Reddit
+	Upvotes       int     `json:"upvotes,omitempty"`
+	UpvoteRatio   float64 `json:"upvote_ratio,omitempty"`
+	NumComments   int     `json:"num_comments,omitempty"`
+	LinkFlairText string  `json:"link_flair_text,omitempty"`
+	Over18        bool    `json:"over_18,omitempty"`
+	Stickied      bool    `json:"stickied,omitempty"`
+
+	LinkedArticle *LinkedArticle `json:"linked_article,omitempty"`
+
+	// Set on near-duplicates of earlier content, Weight is the share they count for in aggregates
+	DuplicateOf string  `json:"duplicate_of,omitempty"`
+	Weight      float64 `json:"weight,omitempty"`
+}
+
+// LinkedArticle is the article a link post points to, its sentiment is
+// analyzed on its own so the reaction can be told apart from the article
+type LinkedArticle struct {
+	URL            string  `json:"url"`
+	Title          string  `json:"title,omitempty"`
+	Text           string  `json:"text"`
+	SentimentScore float64 `json:"sentiment_score,omitempty"`
+	SentimentLabel string  `json:"sentiment_label,omitempty"`
+	Confidence     float64 `json:"confidence,omitempty"`
 }
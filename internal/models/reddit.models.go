@@ -1,16 +1,28 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type RedditPost struct {
-	Topic       string    `json:"topic"`
-	Subreddit   string    `json:"subreddit"`
-	Author      string    `json:"author"`
-	PostTitle   string    `json:"post_title"`
-	PostContent string    `json:"post_content"`
-	Upvotes     int       `json:"upvotes"`
-	CreatedAt   time.Time `json:"created_at"`
-	PostID      string    `json:"id"`
+	Topic         string    `json:"topic"`
+	Subreddit     string    `json:"subreddit"`
+	Author        string    `json:"author"`
+	AuthorID      string    `json:"author_id,omitempty"`
+	PostTitle     string    `json:"post_title"`
+	PostContent   string    `json:"post_content"`
+	Upvotes       int       `json:"upvotes"`
+	UpvoteRatio   float64   `json:"upvote_ratio,omitempty"`
+	NumComments   int       `json:"num_comments,omitempty"`
+	Permalink     string    `json:"permalink,omitempty"`
+	LinkFlairText string    `json:"link_flair_text,omitempty"`
+	Over18        bool      `json:"over_18,omitempty"`
+	Stickied      bool      `json:"stickied,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	PostID        string    `json:"id"`
+	Fullname      string    `json:"fullname"`
+	LinkURL       string    `json:"link_url,omitempty"`
 }
 
 type RedditAPIResponse struct {
@@ -28,11 +40,84 @@ type RedditAPIChild struct {
 
 type RedditAPIClildData struct {
 	Subreddit      string  `json:"subreddit"`
+	Author         string  `json:"author"`
 	AuthorFullname string  `json:"author_fullname"`
 	Title          string  `json:"title"`
 	Selftext       string  `json:"selftext"`
 	Ups            int     `json:"ups"`
+	UpvoteRatio    float64 `json:"upvote_ratio"`
+	NumComments    int     `json:"num_comments"`
+	Permalink      string  `json:"permalink"`
+	LinkFlairText  string  `json:"link_flair_text"`
+	Over18         bool    `json:"over_18"`
+	Stickied       bool    `json:"stickied"`
 	CreatedUTC     float64 `json:"created_utc"`
 	ID             string  `json:"id"`
 	Name           string  `json:"name"`
+	URL            string  `json:"url"`
+	IsSelf         bool    `json:"is_self"`
+	Domain         string  `json:"domain"`
+}
+
+type RedditComment struct {
+	Topic        string    `json:"topic"`
+	Subreddit    string    `json:"subreddit"`
+	Author       string    `json:"author"`
+	AuthorID     string    `json:"author_id,omitempty"`
+	Body         string    `json:"body"`
+	Upvotes      int       `json:"upvotes"`
+	CreatedAt    time.Time `json:"created_at"`
+	CommentID    string    `json:"id"`
+	ParentPostID string    `json:"parent_post_id"`
+	Permalink    string    `json:"permalink"`
+	Depth        int       `json:"depth"`
+}
+
+// RedditCommentListing is a single listing in the /comments/{id} response, the
+// endpoint responds with two of them, the post followed by its comment tree
+type RedditCommentListing struct {
+	Data RedditCommentListingData `json:"data"`
+}
+
+type RedditCommentListingData struct {
+	Children []RedditCommentChild `json:"children"`
+}
+
+type RedditCommentChild struct {
+	Kind string               `json:"kind"`
+	Data RedditCommentAPIData `json:"data"`
+}
+
+type RedditCommentAPIData struct {
+	Subreddit      string  `json:"subreddit"`
+	Author         string  `json:"author"`
+	AuthorFullname string  `json:"author_fullname"`
+	Body           string  `json:"body"`
+	Ups            int     `json:"ups"`
+	CreatedUTC     float64 `json:"created_utc"`
+	ID             string  `json:"id"`
+	LinkID         string  `json:"link_id"`
+	Permalink      string  `json:"permalink"`
+	Depth          int     `json:"depth"`
+	// Replies is an empty string when there are no replies, otherwise it is a
+	// nested RedditCommentListing
+	Replies json.RawMessage `json:"replies"`
+}
+
+type RedditSubredditSearchResponse struct {
+	Data RedditSubredditSearchData `json:"data"`
+}
+
+type RedditSubredditSearchData struct {
+	Children []RedditSubredditChild `json:"children"`
+}
+
+type RedditSubredditChild struct {
+	Data RedditSubredditAPIData `json:"data"`
+}
+
+type RedditSubredditAPIData struct {
+	DisplayName string `json:"display_name"`
+	Subscribers int    `json:"subscribers"`
+	Over18      bool   `json:"over18"`
 }
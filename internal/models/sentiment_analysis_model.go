@@ -5,6 +5,8 @@ type SentimentAnalysisInput struct {
 	Text          string `json:"text"`
 	WasSummarized bool   `json:"was_summarized"`
 	OriginalText  string `json:"original_text,omitempty"`
+	// Redactions counts the personal information redacted per detector
+	Redactions map[string]int `json:"redactions,omitempty"`
 }
 
 type SentimentAnalysisResult struct {
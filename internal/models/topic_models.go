@@ -5,4 +5,12 @@ type Topic struct {
 	Topic    string `json:"topic"`
 	Category string `json:"category"`
 	URL      string `json:"url"`
+
+	// Where the headline behind the topic came from
+	Country  string `json:"country,omitempty"`
+	Language string `json:"language,omitempty"`
+	Query    string `json:"query,omitempty"`
+
+	// Every headline URL merged into the topic, including URL
+	SourceURLs []string `json:"source_urls,omitempty" dynamodbav:"source_urls,omitempty"`
 }
@@ -0,0 +1,105 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+)
+
+const (
+	BLUESKY_SOURCE     = "bluesky"
+	BLUESKY_PAGE_LIMIT = 100
+	BLUESKY_WEB_URL    = "https://bsky.app"
+)
+
+// BlueskySource searches Bluesky posts for each topic, paging with the cursor
+// returned by searchPosts
+type BlueskySource struct {
+	client   *clients.BlueskyClient
+	lookback time.Duration
+	maxPages int
+}
+
+func NewBlueskySource() *BlueskySource {
+	return &BlueskySource{
+		client:   clients.GetBlueskyClient(),
+		lookback: time.Duration(getEnvInt("BLUESKY_LOOKBACK", 24*60*60)) * time.Second,
+		maxPages: getEnvInt("BLUESKY_MAX_PAGES", 5),
+	}
+}
+
+func (bs *BlueskySource) Name() string {
+	return BLUESKY_SOURCE
+}
+
+func (bs *BlueskySource) Fetch(ctx context.Context, topic models.Topic, cursor string) ([]models.RawContent, string, error) {
+	// The cursor is "page|searchPosts cursor" so paging can be capped
+	page := 0
+	apiCursor := ""
+	if cursor != "" {
+		pageStr, rest, _ := strings.Cut(cursor, "|")
+		var err error
+		if page, err = strconv.Atoi(pageStr); err != nil {
+			return nil, "", fmt.Errorf("invalid Bluesky cursor %q: %w", cursor, err)
+		}
+		apiCursor = rest
+	}
+
+	response, err := bs.client.SearchPosts(ctx, topic.Topic, apiCursor, time.Now().Add(-bs.lookback), BLUESKY_PAGE_LIMIT)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contents := make([]models.RawContent, 0, len(response.Posts))
+	for _, post := range response.Posts {
+		contents = append(contents, blueskyPostToRaw(topic.Topic, post))
+	}
+
+	nextCursor := ""
+	if response.Cursor != "" && len(response.Posts) > 0 && page+1 < bs.maxPages {
+		nextCursor = fmt.Sprintf("%d|%s", page+1, response.Cursor)
+	}
+
+	return contents, nextCursor, nil
+}
+
+// blueskyPostURL builds the bsky.app link for an at://did/app.bsky.feed.post/rkey URI
+func blueskyPostURL(post models.BlueskyPost) string {
+	rkey := post.URI[strings.LastIndex(post.URI, "/")+1:]
+	profile := post.Author.Handle
+	if profile == "" {
+		profile = post.Author.DID
+	}
+	return fmt.Sprintf("%s/profile/%s/post/%s", BLUESKY_WEB_URL, profile, rkey)
+}
+
+func blueskyPostToRaw(topic string, post models.BlueskyPost) models.RawContent {
+	source := BLUESKY_SOURCE
+	// the index time is set by the server, it stands in when the client sent
+	// a creation time we can't read
+	timestamp, err := time.Parse(time.RFC3339Nano, post.Record.CreatedAt)
+	if err != nil || timestamp.IsZero() {
+		timestamp = post.IndexedAt
+	}
+
+	return models.RawContent{
+		ContentID: rawcontent.ContentID(topic, source, post.URI),
+		Source:    source,
+		Topic:     topic,
+		Text:      strings.TrimSpace(post.Record.Text),
+		Metadata: models.ContentMetadata{
+			Author:    post.Author.Handle,
+			AuthorID:  post.Author.DID,
+			Timestamp: timestamp,
+			PostID:    post.URI,
+			URL:       blueskyPostURL(post),
+			Languages: post.Record.Langs,
+		},
+	}
+}
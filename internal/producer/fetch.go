@@ -2,63 +2,21 @@ package producer
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/spacesedan/sentiflow/internal/clients"
-	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
 	"github.com/spacesedan/sentiflow/internal/db"
 	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
 )
 
-var CategoryToSubreddits = map[string][]string{
-	"Technology":                  {"technology", "Futurology", "programming", "gadgets", "techsupport"},
-	"Business & Finance":          {"wallstreetbets", "investing", "finance", "personalfinance", "entrepreneur"},
-	"Politics & World Affairs":    {"politics", "worldnews", "geopolitics", "PoliticalHumor", "PoliticalDiscussion"},
-	"Entertainment & Pop Culture": {"movies", "television", "popculturechat", "music"},
-	"Health & Science":            {"science", "askscience", "health", "nutrition", "medicine"},
-	"Sports":                      {"sports", "nba", "nfl", "soccer", "baseball"},
-	"Lifestyle & Society":         {"relationships", "selfimprovement", "lifeprotips", "socialskills", "relationship_advice"},
-	"Memes & Internet Trends":     {"memes", "dankmemes", "me_irl", "OutOfTheLoop", "PoliticalHumor"},
-	"Crime & Law":                 {"legaladvice", "TrueCrime", "law", "CrimeScene"},
-}
-
-var (
-	CategoryToSubredditsStr = make(map[string]string)
-	Categories              []string
-	categoryHelpersOnce     sync.Once
-)
-
-func InitCategoryHelpers() {
-	categoryHelpersOnce.Do(func() {
-		Categories = Categories[:0]
-
-		for category, subreddits := range CategoryToSubreddits {
-			Categories = append(Categories, category)
-			CategoryToSubredditsStr[category] = strings.Join(subreddits, "+")
-		}
-	})
-}
-
-// mapTopicsToCategory creates a map of topics that can be used to create queries for the Reddit API
-func mapTopicsToCategory(topics []models.Topic) map[string][]models.Topic {
-	topicMap := make(map[string][]models.Topic)
-
-	for _, topic := range topics {
-		topicMap[topic.Category] = append(topicMap[topic.Category], topic)
-	}
-
-	return topicMap
-}
-
-// FetchRedditContentForTopics fetches Reddit posts based on stored topics & sends to Kafka
-func FetchRedditContentForTopics(ctx context.Context) {
-	slog.Info("Fetching Reddit content for stored topics...")
+// FetchContentForTopics fetches content from every registered source based on
+// stored topics & sends it to Kafka
+func FetchContentForTopics(ctx context.Context) {
+	slog.Info("Fetching content for stored topics...")
 
 	topics, err := db.GetAllTopics()
 	if err != nil {
@@ -67,69 +25,109 @@ func FetchRedditContentForTopics(ctx context.Context) {
 	}
 
 	if len(topics) == 0 {
-		slog.Warn("No new topics found. Skipping Reddit fetch.")
+		slog.Warn("No new topics found. Skipping content fetch.")
 		return
 	}
 
-	topicMap := mapTopicsToCategory(topics)
-
-	// Process each query
-	for _, category := range Categories {
-		subreddits, exists := CategoryToSubredditsStr[category]
-		if !exists {
-			slog.Warn("No Matching subbreddits found for topic category", slog.String("category", category))
-			continue
-		}
+	sources := registeredSources()
+	filter := NewQualityFilter()
+	stats := newCycleStats()
+	throttledBefore := throttledTime(sources)
+
+	// Topics are fetched by a bounded pool of workers, the sources own rate
+	// limiters keep them from overrunning the APIs
+	workers := max(getEnvInt("PRODUCER_FETCH_WORKERS", 4), 1)
+
+	jobs := make(chan topicJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := fetchAndProcessTopic(ctx, job.source, job.topic, filter, stats); err != nil {
+					stats.TopicsFailed.Add(1)
+					slog.Error("Failed processing topic",
+						slog.String("source", job.source.Name()),
+						slog.String("topic", job.topic.Topic),
+						slog.String("error", err.Error()))
+					continue
+				}
+				stats.TopicsDone.Add(1)
+			}
+		}()
+	}
 
-		for _, topic := range topicMap[category] {
-			if err := fetchAndProcessTopics(ctx, subreddits, topic); err != nil {
-				slog.Error("Failed processing topic",
-					slog.String("topic", topic.Topic))
+dispatch:
+	for _, source := range sources {
+		for _, topic := range topics {
+			select {
+			case <-ctx.Done():
+				break dispatch
+			case jobs <- topicJob{source: source, topic: topic}:
 			}
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	stats.Throttled = throttledTime(sources) - throttledBefore
+	stats.Log()
+
+	slog.Info("Successfully fetched & sent content to Kafka!")
+}
 
-	slog.Info("Successfully fetched & sent Reddit content to Kafka!")
+type topicJob struct {
+	source ContentSource
+	topic  models.Topic
 }
 
-func fetchAndProcessTopics(ctx context.Context, subreddits string, topic models.Topic) error {
-	after := ""
+func fetchAndProcessTopic(ctx context.Context, source ContentSource, topic models.Topic, filter *QualityFilter, stats *CycleStats) error {
+	cursor := ""
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Warn("Context cancelled, stopping fetch for topic",
+				slog.String("source", source.Name()),
 				slog.String("topic", topic.Topic))
 			return ctx.Err()
 		default:
 		}
 
-		posts, nextAfter, err := fetchWithRetries(ctx, subreddits, topic.Topic, after)
+		contents, nextCursor, err := fetchWithRetries(ctx, source, topic, cursor)
 		if err != nil {
 			return fmt.Errorf("fetch failed after retries: %w", err)
 		}
 
-		processPosts(ctx, posts)
-		if nextAfter == "" {
+		stats.Published.Add(int64(processContent(ctx, source.Name(), contents, filter, stats)))
+		if nextCursor == "" {
 			break
 		}
-		after = nextAfter
+		cursor = nextCursor
+	}
+
+	if checkpointed, ok := source.(CheckpointedSource); ok {
+		if err := checkpointed.Checkpoint(ctx, topic); err != nil {
+			return fmt.Errorf("checkpoint failed: %w", err)
+		}
 	}
 	return nil
 }
 
-func fetchWithRetries(ctx context.Context, subreddits, query, after string) ([]models.RedditPost, string, error) {
-	var posts []models.RedditPost
-	var nextAfter string
+func fetchWithRetries(ctx context.Context, source ContentSource, topic models.Topic, cursor string) ([]models.RawContent, string, error) {
+	var contents []models.RawContent
+	var nextCursor string
 	var err error
 
 	for attempt := 1; attempt <= 3; attempt++ {
-		posts, nextAfter, err = clients.GetRedditClient().FetchSubredditPosts(ctx, subreddits, query, after)
+		contents, nextCursor, err = source.Fetch(ctx, topic, cursor)
 		if err == nil {
-			return posts, nextAfter, nil
+			return contents, nextCursor, nil
 		}
 
-		slog.Warn("Retrying Reddit fetch",
-			slog.String("query", query),
+		slog.Warn("Retrying content fetch",
+			slog.String("source", source.Name()),
+			slog.String("query", topic.Topic),
 			slog.Int("attempt", attempt),
 			slog.String("error", err.Error()))
 
@@ -143,56 +141,37 @@ func fetchWithRetries(ctx context.Context, subreddits, query, after string) ([]m
 	return nil, "", err
 }
 
-func processPosts(ctx context.Context, posts []models.RedditPost) {
-	for _, post := range posts {
+// processContent dedupes the fetched content through Valkey and publishes
+// anything new to the raw content topic, returning how many were published
+func processContent(ctx context.Context, source string, contents []models.RawContent, filter *QualityFilter, stats *CycleStats) int {
+	published := 0
+	for _, content := range contents {
 		select {
 		case <-ctx.Done():
-			slog.Warn("Context cancelled during post processing")
-			return
+			slog.Warn("Context cancelled during content processing")
+			return published
 		default:
 		}
 
-		dedupeKey := fmt.Sprintf("%s:%s", post.Topic, post.PostID)
-
-		if post.PostContent == "" || clients.GetValkeyClient().IsPostProcessed(ctx, "reddit", dedupeKey) {
+		if content.Text == "" {
 			continue
 		}
 
-		rawContent := redditPostToRaw(post)
-		if err := kafka_client.PublishToKafka(ctx, kafka_client.KAFKA_TOPIC_RAW_CONTENT, rawContent); err != nil {
-			slog.Warn("Failed to publish to Kafka",
-				slog.String("post_id", rawContent.ContentID),
-				slog.String("error", err.Error()))
+		if rule, drop := filter.Check(content); drop {
+			stats.RecordDrop(rule)
 			continue
 		}
 
-		if err := clients.GetValkeyClient().MarkProcessed(ctx, "reddit", dedupeKey); err != nil {
-			slog.Warn("Error marking post as processed",
-				slog.String("post_id", post.PostID),
-				slog.String("error", err.Error()))
+		if err := rawcontent.Publish(ctx, content); err != nil {
+			if !errors.Is(err, rawcontent.ErrDuplicateContent) {
+				slog.Warn("Failed to publish to Kafka",
+					slog.String("source", source),
+					slog.String("post_id", content.ContentID),
+					slog.String("error", err.Error()))
+			}
+			continue
 		}
-
-	}
-}
-
-func generateRedditContentID(topic, source, postID string) string {
-	raw := fmt.Sprintf("%s:%s:%s", topic, source, postID)
-	hash := sha256.Sum256([]byte(raw))
-	return hex.EncodeToString(hash[:])
-}
-
-func redditPostToRaw(p models.RedditPost) models.RawContent {
-	source := "reddit"
-	return models.RawContent{
-		ContentID: generateRedditContentID(p.Topic, source, p.PostID),
-		Source:    source,
-		Topic:     p.Topic,
-		Text:      p.PostContent,
-		Metadata: models.ContentMetadata{
-			Author:    p.Author,
-			Timestamp: p.CreatedAt,
-			Subreddit: p.Subreddit,
-			PostID:    p.PostID,
-		},
+		published++
 	}
+	return published
 }
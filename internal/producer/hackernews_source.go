@@ -0,0 +1,113 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+	"github.com/spacesedan/sentiflow/internal/utils"
+)
+
+const (
+	HACKER_NEWS_SOURCE   = "hackernews"
+	HACKER_NEWS_ITEM_URL = "https://news.ycombinator.com/item?id="
+)
+
+// HackerNewsSource searches Hacker News stories and comments for each topic in
+// the configured categories, the cursor is the result page
+type HackerNewsSource struct {
+	client     *clients.HackerNewsClient
+	categories []string
+	lookback   time.Duration
+	maxPages   int
+}
+
+func NewHackerNewsSource() *HackerNewsSource {
+	var categories []string
+	for _, category := range strings.Split(getEnv("HN_CATEGORIES", "Technology"), ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+
+	return &HackerNewsSource{
+		client:     clients.GetHackerNewsClient(),
+		categories: categories,
+		lookback:   time.Duration(getEnvInt("HN_LOOKBACK", 24*60*60)) * time.Second,
+		maxPages:   getEnvInt("HN_MAX_PAGES", 5),
+	}
+}
+
+func (hs *HackerNewsSource) Name() string {
+	return HACKER_NEWS_SOURCE
+}
+
+func (hs *HackerNewsSource) Fetch(ctx context.Context, topic models.Topic, cursor string) ([]models.RawContent, string, error) {
+	if len(hs.categories) > 0 && !slices.Contains(hs.categories, topic.Category) {
+		return nil, "", nil
+	}
+
+	page := 0
+	if cursor != "" {
+		var err error
+		if page, err = strconv.Atoi(cursor); err != nil {
+			return nil, "", fmt.Errorf("invalid Hacker News cursor %q: %w", cursor, err)
+		}
+	}
+
+	response, err := hs.client.Search(ctx, topic.Topic, page, time.Now().Add(-hs.lookback))
+	if err != nil {
+		return nil, "", err
+	}
+
+	contents := make([]models.RawContent, 0, len(response.Hits))
+	for _, hit := range response.Hits {
+		contents = append(contents, hnHitToRaw(topic.Topic, hit))
+	}
+
+	nextCursor := ""
+	if next := response.Page + 1; next < response.NbPages && next < hs.maxPages {
+		nextCursor = strconv.Itoa(next)
+	}
+
+	return contents, nextCursor, nil
+}
+
+func hnHitToRaw(topic string, hit models.HNHit) models.RawContent {
+	source := HACKER_NEWS_SOURCE
+	permalink := HACKER_NEWS_ITEM_URL + hit.ObjectID
+
+	content := models.RawContent{
+		ContentID: rawcontent.ContentID(topic, source, hit.ObjectID),
+		Source:    source,
+		Topic:     topic,
+		Metadata: models.ContentMetadata{
+			Author:    hit.Author,
+			Timestamp: time.Unix(hit.CreatedAtI, 0),
+			PostID:    hit.ObjectID,
+			Permalink: permalink,
+			URL:       permalink,
+		},
+	}
+
+	if slices.Contains(hit.Tags, "comment") {
+		content.Text = utils.StripHTML(hit.CommentText)
+		if hit.StoryID != 0 {
+			content.Metadata.ParentPostID = strconv.Itoa(hit.StoryID)
+		}
+		return content
+	}
+
+	content.Text = rawcontent.ComposeText(rawcontent.COMPOSITION_TITLE_BODY, hit.Title, utils.StripHTML(hit.StoryText))
+	content.Metadata.TextComposition = string(rawcontent.COMPOSITION_TITLE_BODY)
+	if hit.URL != "" {
+		content.Metadata.URL = hit.URL
+	}
+	return content
+}
@@ -0,0 +1,100 @@
+package producer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// Hosts that serve media or point back into Reddit, there is no article to extract
+var nonArticleHosts = []string{
+	"reddit.com",
+	"redd.it",
+	"imgur.com",
+	"gfycat.com",
+	"youtube.com",
+	"youtu.be",
+	"twitter.com",
+	"x.com",
+	"streamable.com",
+	"giphy.com",
+}
+
+// linkedArticle returns the extracted article behind a link post. Articles are
+// cached in Valkey by URL since the same link is usually shared across several
+// subreddits, failed extractions are cached as empty so they are not retried
+// every tick.
+func (rs *RedditSource) linkedArticle(ctx context.Context, post models.RedditPost) *models.LinkedArticle {
+	if !rs.linkedArticles || !isArticleLink(post.LinkURL) {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(post.LinkURL))
+	cacheKey := fmt.Sprintf("%s:article:%s", REDDIT_SOURCE, hex.EncodeToString(sum[:]))
+
+	if cached, ok := clients.GetValkeyClient().GetCached(ctx, cacheKey); ok {
+		if cached == "" {
+			return nil
+		}
+		var article models.LinkedArticle
+		if err := json.Unmarshal([]byte(cached), &article); err == nil {
+			return &article
+		}
+	}
+
+	var cached string
+	article, err := clients.GetArticleClient().FetchArticle(ctx, post.LinkURL)
+	if err != nil {
+		if !errors.Is(err, clients.ErrNotAnArticle) {
+			slog.Warn("Failed to extract linked article",
+				slog.String("post_id", post.PostID),
+				slog.String("url", post.LinkURL),
+				slog.String("error", err.Error()))
+		}
+		article = nil
+	} else if encoded, err := json.Marshal(article); err == nil {
+		cached = string(encoded)
+	}
+
+	if err := clients.GetValkeyClient().SetCached(ctx, cacheKey, cached, rs.articleTTL); err != nil {
+		slog.Warn("Failed to cache linked article",
+			slog.String("url", post.LinkURL),
+			slog.String("error", err.Error()))
+	}
+
+	return article
+}
+
+// isArticleLink reports whether a link could point to an article page, links
+// to internal addresses are refused here and again by the article client
+// after DNS resolution
+func isArticleLink(link string) bool {
+	if link == "" {
+		return false
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if clients.IsBlockedHost(host) {
+		return false
+	}
+	for _, blocked := range nonArticleHosts {
+		if host == blocked || strings.HasSuffix(host, "."+blocked) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,304 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+	"github.com/spacesedan/sentiflow/internal/utils"
+)
+
+const (
+	MASTODON_SOURCE     = "mastodon"
+	MASTODON_PAGE_LIMIT = 40
+)
+
+// Mastodon endpoints a topic is pulled from on every instance
+const (
+	mastodonSearch = "search"
+	mastodonTag    = "tag"
+)
+
+// MastodonSource queries the public search and hashtag timeline of every
+// configured instance for each topic. The newest status ID seen per instance
+// and endpoint is kept in Valkey, so each run only pages back to where the
+// previous one stopped.
+type MastodonSource struct {
+	client    *clients.MastodonClient
+	instances []string
+	maxPages  int
+
+	mu     sync.Mutex
+	states map[string]*mastodonState
+}
+
+// mastodonState holds the since IDs loaded for a topic, the newest IDs seen
+// while paging during the current run and the newest IDs to store, all keyed
+// by instance|endpoint. A head only becomes the stored ID once paging got back
+// to the since ID, otherwise the statuses in between would never be fetched.
+type mastodonState struct {
+	since  map[string]string
+	heads  map[string]string
+	newest map[string]string
+}
+
+// mastodonCursor walks the instances and their endpoints one page at a time
+type mastodonCursor struct {
+	step  int
+	page  int
+	maxID string
+}
+
+func NewMastodonSource() *MastodonSource {
+	var instances []string
+	for _, instance := range strings.Split(getEnv("MASTODON_INSTANCES", ""), ",") {
+		if instance = strings.TrimSpace(instance); instance != "" {
+			instances = append(instances, strings.TrimRight(instance, "/"))
+		}
+	}
+
+	return &MastodonSource{
+		client:    clients.GetMastodonClient(),
+		instances: instances,
+		maxPages:  getEnvInt("MASTODON_MAX_PAGES", 5),
+		states:    make(map[string]*mastodonState),
+	}
+}
+
+func (ms *MastodonSource) Name() string {
+	return MASTODON_SOURCE
+}
+
+func (ms *MastodonSource) ThrottledTime() time.Duration {
+	return ms.client.ThrottledTime()
+}
+
+func (ms *MastodonSource) Fetch(ctx context.Context, topic models.Topic, cursor string) ([]models.RawContent, string, error) {
+	steps := ms.steps(topic)
+	if len(steps) == 0 {
+		return nil, "", nil
+	}
+
+	position, err := parseMastodonCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	state := ms.state(ctx, topic, cursor == "")
+	if position.step >= len(steps) {
+		return nil, "", nil
+	}
+
+	step := steps[position.step]
+	field := step.instance + "|" + step.endpoint
+	sinceID := state.since[field]
+
+	var statuses []models.MastodonStatus
+	switch step.endpoint {
+	case mastodonSearch:
+		statuses, err = ms.client.SearchStatuses(ctx, step.instance, topic.Topic, position.maxID, MASTODON_PAGE_LIMIT)
+	case mastodonTag:
+		statuses, err = ms.client.HashtagTimeline(ctx, step.instance, step.query, position.maxID, sinceID, MASTODON_PAGE_LIMIT)
+	}
+	if err != nil {
+		// One instance failing shouldn't cost us the others
+		slog.Warn("Mastodon request failed, moving on to the next instance",
+			slog.String("instance", step.instance),
+			slog.String("endpoint", step.endpoint),
+			slog.String("topic", topic.Topic),
+			slog.String("error", err.Error()))
+		return nil, ms.nextStep(position.step, len(steps)), nil
+	}
+
+	contents := make([]models.RawContent, 0, len(statuses))
+	reachedSince := false
+	for _, status := range statuses {
+		if sinceID != "" && compareStatusIDs(status.ID, sinceID) <= 0 {
+			reachedSince = true
+			break
+		}
+		if compareStatusIDs(status.ID, state.heads[field]) > 0 {
+			state.heads[field] = status.ID
+		}
+		if status.Reblog != nil {
+			continue
+		}
+		contents = append(contents, mastodonStatusToRaw(topic.Topic, step.instance, status))
+	}
+
+	// without a since ID there is no gap to leave behind, the first run only
+	// reads back as far as maxPages
+	caughtUp := reachedSince || len(statuses) < MASTODON_PAGE_LIMIT || sinceID == ""
+	if caughtUp || position.page+1 >= ms.maxPages {
+		if caughtUp && compareStatusIDs(state.heads[field], state.newest[field]) > 0 {
+			state.newest[field] = state.heads[field]
+		}
+		return contents, ms.nextStep(position.step, len(steps)), nil
+	}
+
+	next := mastodonCursor{
+		step:  position.step,
+		page:  position.page + 1,
+		maxID: statuses[len(statuses)-1].ID,
+	}
+	return contents, next.String(), nil
+}
+
+// Checkpoint stores the newest status IDs the topic was caught up to
+func (ms *MastodonSource) Checkpoint(ctx context.Context, topic models.Topic) error {
+	ms.mu.Lock()
+	state, ok := ms.states[topic.Topic]
+	delete(ms.states, topic.Topic)
+	ms.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return clients.GetValkeyClient().SetCursor(ctx, MASTODON_SOURCE, topic.Topic, state.newest)
+}
+
+type mastodonStep struct {
+	instance string
+	endpoint string
+	query    string
+}
+
+// steps lists every instance/endpoint pair a topic is fetched from, the
+// hashtag timeline is only used for topics short enough to be a hashtag
+func (ms *MastodonSource) steps(topic models.Topic) []mastodonStep {
+	hashtag := ""
+	if keywords := topicKeywords(topic.Topic); len(keywords) > 0 && len(keywords) <= 3 {
+		hashtag = strings.Join(keywords, "")
+	}
+
+	var steps []mastodonStep
+	for _, instance := range ms.instances {
+		steps = append(steps, mastodonStep{instance: instance, endpoint: mastodonSearch, query: topic.Topic})
+		if hashtag != "" {
+			steps = append(steps, mastodonStep{instance: instance, endpoint: mastodonTag, query: hashtag})
+		}
+	}
+	return steps
+}
+
+func (ms *MastodonSource) nextStep(step, total int) string {
+	if step+1 >= total {
+		return ""
+	}
+	return mastodonCursor{step: step + 1}.String()
+}
+
+// state returns the run state for a topic, loading the stored since IDs at the
+// start of a run. The lookup happens outside the lock so a slow Valkey call
+// doesn't hold up the other topics.
+func (ms *MastodonSource) state(ctx context.Context, topic models.Topic, reset bool) *mastodonState {
+	if !reset {
+		ms.mu.Lock()
+		state, ok := ms.states[topic.Topic]
+		ms.mu.Unlock()
+		if ok {
+			return state
+		}
+	}
+
+	since, err := clients.GetValkeyClient().GetCursor(ctx, MASTODON_SOURCE, topic.Topic)
+	if err != nil {
+		slog.Warn("Failed to load Mastodon cursors, starting from the newest statuses",
+			slog.String("topic", topic.Topic),
+			slog.String("error", err.Error()))
+		since = map[string]string{}
+	}
+
+	newest := make(map[string]string, len(since))
+	for field, id := range since {
+		newest[field] = id
+	}
+	state := &mastodonState{since: since, heads: make(map[string]string), newest: newest}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	// another fetch may have loaded the state while we were waiting on Valkey
+	if existing, ok := ms.states[topic.Topic]; ok && !reset {
+		return existing
+	}
+	ms.states[topic.Topic] = state
+	return state
+}
+
+func (c mastodonCursor) String() string {
+	return fmt.Sprintf("%d|%d|%s", c.step, c.page, c.maxID)
+}
+
+func parseMastodonCursor(cursor string) (mastodonCursor, error) {
+	if cursor == "" {
+		return mastodonCursor{}, nil
+	}
+
+	parts := strings.SplitN(cursor, "|", 3)
+	if len(parts) != 3 {
+		return mastodonCursor{}, fmt.Errorf("invalid Mastodon cursor %q", cursor)
+	}
+
+	step, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return mastodonCursor{}, fmt.Errorf("invalid Mastodon cursor %q: %w", cursor, err)
+	}
+	page, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return mastodonCursor{}, fmt.Errorf("invalid Mastodon cursor %q: %w", cursor, err)
+	}
+	return mastodonCursor{step: step, page: page, maxID: parts[2]}, nil
+}
+
+// compareStatusIDs orders Mastodon's numeric string IDs without parsing them,
+// they can outgrow an int64 on some servers
+func compareStatusIDs(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func mastodonStatusToRaw(topic, instance string, status models.MastodonStatus) models.RawContent {
+	source := MASTODON_SOURCE
+	host := instance
+	if parsed, err := url.Parse(instance); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	// Local accounts have no domain in their acct
+	handle := "@" + status.Account.Acct
+	if !strings.Contains(status.Account.Acct, "@") {
+		handle += "@" + host
+	}
+
+	statusURL := status.URL
+	if statusURL == "" {
+		statusURL = status.URI
+	}
+
+	return models.RawContent{
+		ContentID: rawcontent.ContentID(topic, source, status.URI),
+		Source:    source,
+		Topic:     topic,
+		Text:      utils.StripHTML(status.Content),
+		Metadata: models.ContentMetadata{
+			Author:    handle,
+			Timestamp: status.CreatedAt,
+			PostID:    status.URI,
+			URL:       statusURL,
+			Instance:  host,
+		},
+	}
+}
@@ -0,0 +1,164 @@
+package producer
+
+import (
+	"math"
+	"strings"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// Quality rule names, these are the keys drop counts are reported under
+const (
+	RULE_MIN_LENGTH      = "min_length"
+	RULE_AUTHOR_DENYLIST = "author_denylist"
+	RULE_BOT_AUTHOR      = "bot_author"
+	RULE_REMOVED_CONTENT = "removed_content"
+	RULE_OVER_18         = "over_18"
+	RULE_STICKIED        = "stickied"
+	RULE_MIN_SCORE       = "min_score"
+)
+
+// QualityRule drops content that isn't worth analyzing
+type QualityRule struct {
+	Name string
+	Drop func(content models.RawContent) bool
+}
+
+// QualityFilter runs content through its rules in order, the first rule that
+// matches decides why the content was dropped
+type QualityFilter struct {
+	rules []QualityRule
+}
+
+// Phrases bots sign their comments with
+var botSignatures = []string{
+	"i am a bot",
+	"i'm a bot",
+	"this action was performed automatically",
+}
+
+// NewQualityFilter builds the filter from env, each rule can be switched off
+// on its own
+func NewQualityFilter() *QualityFilter {
+	var rules []QualityRule
+
+	if minLength := getEnvInt("FILTER_MIN_LENGTH", 20); minLength > 0 {
+		rules = append(rules, QualityRule{
+			Name: RULE_MIN_LENGTH,
+			Drop: func(content models.RawContent) bool {
+				return len([]rune(strings.TrimSpace(content.Text))) < minLength
+			},
+		})
+	}
+
+	markers := getEnvList("FILTER_REMOVED_MARKERS", "[deleted],[removed],[ Removed by Reddit ]")
+	if len(markers) > 0 {
+		rules = append(rules, QualityRule{
+			Name: RULE_REMOVED_CONTENT,
+			Drop: func(content models.RawContent) bool {
+				return isRemovedContent(content, markers)
+			},
+		})
+	}
+
+	denylist := make(map[string]struct{})
+	for _, author := range getEnvList("FILTER_AUTHOR_DENYLIST", "AutoModerator") {
+		denylist[strings.ToLower(author)] = struct{}{}
+	}
+	if len(denylist) > 0 {
+		rules = append(rules, QualityRule{
+			Name: RULE_AUTHOR_DENYLIST,
+			Drop: func(content models.RawContent) bool {
+				_, denied := denylist[strings.ToLower(content.Metadata.Author)]
+				return denied
+			},
+		})
+	}
+
+	if getEnv("FILTER_BOT_HEURISTICS", "true") == "true" {
+		rules = append(rules, QualityRule{
+			Name: RULE_BOT_AUTHOR,
+			Drop: isBotContent,
+		})
+	}
+
+	if getEnv("FILTER_DROP_OVER_18", "true") == "true" {
+		rules = append(rules, QualityRule{
+			Name: RULE_OVER_18,
+			Drop: func(content models.RawContent) bool {
+				return content.Metadata.Over18
+			},
+		})
+	}
+
+	if getEnv("FILTER_DROP_STICKIED", "true") == "true" {
+		rules = append(rules, QualityRule{
+			Name: RULE_STICKIED,
+			Drop: func(content models.RawContent) bool {
+				return content.Metadata.Stickied
+			},
+		})
+	}
+
+	// Scores are only reported by Reddit, so the rule only applies to it
+	if minScore := getEnvInt("FILTER_MIN_SCORE", math.MinInt); minScore != math.MinInt {
+		rules = append(rules, QualityRule{
+			Name: RULE_MIN_SCORE,
+			Drop: func(content models.RawContent) bool {
+				return content.Source == REDDIT_SOURCE && content.Metadata.Upvotes < minScore
+			},
+		})
+	}
+
+	return &QualityFilter{rules: rules}
+}
+
+// Check returns the name of the first rule the content fails, or false when
+// the content passes every rule
+func (qf *QualityFilter) Check(content models.RawContent) (string, bool) {
+	for _, rule := range qf.rules {
+		if rule.Drop(content) {
+			return rule.Name, true
+		}
+	}
+	return "", false
+}
+
+// isRemovedContent matches content whose text or author was replaced by a
+// removal marker, posts keep their title so every line is checked
+func isRemovedContent(content models.RawContent, markers []string) bool {
+	for _, marker := range markers {
+		if content.Metadata.Author == marker {
+			return true
+		}
+	}
+
+	for _, line := range strings.Split(content.Text, "\n") {
+		line = strings.TrimSpace(line)
+		for _, marker := range markers {
+			if strings.EqualFold(line, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBotContent flags authors named like bots (RemindMeBot, sports_bot) and
+// content that carries a bot signature
+func isBotContent(content models.RawContent) bool {
+	author := content.Metadata.Author
+	lower := strings.ToLower(author)
+	if strings.HasSuffix(author, "Bot") || strings.HasSuffix(author, "BOT") ||
+		strings.HasSuffix(lower, "_bot") || strings.HasSuffix(lower, "-bot") {
+		return true
+	}
+
+	text := strings.ToLower(content.Text)
+	for _, signature := range botSignatures {
+		if strings.Contains(text, signature) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+)
+
+const REDDIT_SOURCE = "reddit"
+
+// Reddit fetch modes
+const (
+	REDDIT_MODE_SEARCH = "search" // relevance search over the last day, every tick
+	REDDIT_MODE_STREAM = "stream" // newest first, stopping at the last post seen
+)
+
+// RedditSource searches the subreddits mapped to a topics category, pulling in
+// the top comments of every newly matched post along the way
+type RedditSource struct {
+	client          *clients.RedditClient
+	commentLimit    int
+	commentDepth    int
+	textComposition rawcontent.TextComposition
+	mode            string
+	streams         *redditStreams
+	discoveryLimit  int
+	discoveryTTL    time.Duration
+	resolved        *resolvedSubreddits
+	linkedArticles  bool
+	articleTTL      time.Duration
+}
+
+func NewRedditSource() *RedditSource {
+	mode := getEnv("REDDIT_FETCH_MODE", REDDIT_MODE_SEARCH)
+	if mode != REDDIT_MODE_SEARCH && mode != REDDIT_MODE_STREAM {
+		slog.Warn("Unknown Reddit fetch mode, falling back to search",
+			slog.String("mode", mode))
+		mode = REDDIT_MODE_SEARCH
+	}
+
+	return &RedditSource{
+		client:          clients.GetRedditClient(),
+		commentLimit:    getEnvInt("REDDIT_COMMENT_LIMIT", 20),
+		commentDepth:    getEnvInt("REDDIT_COMMENT_DEPTH", 2),
+		textComposition: rawcontent.ParseTextComposition(getEnv("REDDIT_TEXT_COMPOSITION", "")),
+		mode:            mode,
+		streams:         newRedditStreams(),
+		discoveryLimit:  getEnvInt("REDDIT_DISCOVERY_LIMIT", 5),
+		discoveryTTL:    time.Duration(getEnvInt("REDDIT_DISCOVERY_TTL", 6*60*60)) * time.Second,
+		resolved:        newResolvedSubreddits(),
+		linkedArticles:  getEnv("REDDIT_LINKED_ARTICLES", "false") == "true",
+		articleTTL:      time.Duration(getEnvInt("REDDIT_ARTICLE_TTL", 24*60*60)) * time.Second,
+	}
+}
+
+func (rs *RedditSource) Name() string {
+	return REDDIT_SOURCE
+}
+
+func (rs *RedditSource) ThrottledTime() time.Duration {
+	return rs.client.ThrottledTime()
+}
+
+func (rs *RedditSource) Fetch(ctx context.Context, topic models.Topic, cursor string) ([]models.RawContent, string, error) {
+	subreddits := rs.subredditsForTopic(ctx, topic)
+	if subreddits == "" {
+		slog.Warn("No Matching subbreddits found for topic",
+			slog.String("category", topic.Category),
+			slog.String("topic", topic.Topic))
+		return nil, "", nil
+	}
+
+	var posts []models.RedditPost
+	var nextAfter string
+	var err error
+
+	if rs.mode == REDDIT_MODE_STREAM {
+		posts, nextAfter, err = rs.fetchNewPosts(ctx, subreddits, topic, cursor)
+	} else {
+		posts, nextAfter, err = rs.client.FetchSubredditPosts(ctx, subreddits, topic.Topic, cursor)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	contents := make([]models.RawContent, 0, len(posts))
+	for _, post := range posts {
+		content := redditPostToRaw(post, rs.textComposition)
+		content.Metadata.LinkedArticle = rs.linkedArticle(ctx, post)
+		contents = append(contents, content)
+
+		// search mode sees mostly the same posts every tick, their comments
+		// were pulled in when the post was first published
+		if rawcontent.IsProcessed(ctx, content) {
+			continue
+		}
+		contents = append(contents, rs.fetchComments(ctx, post)...)
+	}
+
+	return contents, nextAfter, nil
+}
+
+// fetchComments pulls the top comments for a post, a failure here only costs
+// us the comments so it is logged rather than failing the whole page
+func (rs *RedditSource) fetchComments(ctx context.Context, post models.RedditPost) []models.RawContent {
+	if rs.commentLimit <= 0 || rs.commentDepth <= 0 {
+		return nil
+	}
+
+	comments, err := rs.client.FetchPostComments(ctx, post, rs.commentLimit, rs.commentDepth)
+	if err != nil {
+		slog.Warn("Failed to fetch comments for post",
+			slog.String("post_id", post.PostID),
+			slog.String("topic", post.Topic),
+			slog.String("error", err.Error()))
+		return nil
+	}
+
+	contents := make([]models.RawContent, 0, len(comments))
+	for _, comment := range comments {
+		contents = append(contents, redditCommentToRaw(comment))
+	}
+	return contents
+}
+
+func generateRedditContentID(topic, source, postID string) string {
+	return rawcontent.ContentID(topic, source, postID)
+}
+
+func redditPostToRaw(p models.RedditPost, composition rawcontent.TextComposition) models.RawContent {
+	source := REDDIT_SOURCE
+	return models.RawContent{
+		ContentID: generateRedditContentID(p.Topic, source, p.PostID),
+		Source:    source,
+		Topic:     p.Topic,
+		Text:      rawcontent.ComposeText(composition, p.PostTitle, p.PostContent),
+		Metadata: models.ContentMetadata{
+			Author:          p.Author,
+			AuthorID:        p.AuthorID,
+			Timestamp:       p.CreatedAt,
+			Subreddit:       p.Subreddit,
+			PostID:          p.PostID,
+			Permalink:       p.Permalink,
+			TextComposition: string(composition),
+			Upvotes:         p.Upvotes,
+			UpvoteRatio:     p.UpvoteRatio,
+			NumComments:     p.NumComments,
+			LinkFlairText:   p.LinkFlairText,
+			Over18:          p.Over18,
+			Stickied:        p.Stickied,
+		},
+	}
+}
+
+func redditCommentToRaw(c models.RedditComment) models.RawContent {
+	source := REDDIT_SOURCE
+	return models.RawContent{
+		ContentID: generateRedditContentID(c.Topic, source, c.CommentID),
+		Source:    source,
+		Topic:     c.Topic,
+		Text:      c.Body,
+		Metadata: models.ContentMetadata{
+			Author:       c.Author,
+			AuthorID:     c.AuthorID,
+			Timestamp:    c.CreatedAt,
+			Subreddit:    c.Subreddit,
+			PostID:       c.CommentID,
+			ParentPostID: c.ParentPostID,
+			Permalink:    c.Permalink,
+			Upvotes:      c.Upvotes,
+		},
+	}
+}
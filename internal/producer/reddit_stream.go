@@ -0,0 +1,129 @@
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// redditStreamState tracks the last post persisted for a topic and the newest
+// post seen during the current run
+type redditStreamState struct {
+	lastName    string
+	lastCreated time.Time
+	newestName  string
+	newest      time.Time
+}
+
+type redditStreams struct {
+	states map[string]*redditStreamState
+	mu     sync.Mutex
+}
+
+func newRedditStreams() *redditStreams {
+	return &redditStreams{
+		states: make(map[string]*redditStreamState),
+	}
+}
+
+func (s *redditStreams) get(topic string) (*redditStreamState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[topic]
+	return state, ok
+}
+
+func (s *redditStreams) set(topic string, state *redditStreamState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[topic] = state
+}
+
+func (s *redditStreams) remove(topic string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, topic)
+}
+
+// fetchNewPosts pages through the newest posts for a topic, stopping as soon as
+// it reaches the post the previous run left off at
+func (rs *RedditSource) fetchNewPosts(ctx context.Context, subreddits string, topic models.Topic, after string) ([]models.RedditPost, string, error) {
+	state, ok := rs.streams.get(topic.Topic)
+	if !ok || after == "" {
+		state = loadRedditStreamState(ctx, topic.Topic)
+		rs.streams.set(topic.Topic, state)
+	}
+
+	posts, nextAfter, err := rs.client.FetchNewSubredditPosts(ctx, subreddits, topic.Topic, after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var fresh []models.RedditPost
+	for _, post := range posts {
+		if post.Fullname == state.lastName || post.CreatedAt.Before(state.lastCreated) {
+			// Everything after this point was handled by a previous run
+			nextAfter = ""
+			break
+		}
+
+		if post.CreatedAt.After(state.newest) {
+			state.newest = post.CreatedAt
+			state.newestName = post.Fullname
+		}
+		fresh = append(fresh, post)
+	}
+
+	return fresh, nextAfter, nil
+}
+
+// Checkpoint persists the newest post seen for the topic once all of its pages
+// have been processed
+func (rs *RedditSource) Checkpoint(ctx context.Context, topic models.Topic) error {
+	if rs.mode != REDDIT_MODE_STREAM {
+		return nil
+	}
+
+	state, ok := rs.streams.get(topic.Topic)
+	if !ok {
+		return nil
+	}
+	defer rs.streams.remove(topic.Topic)
+
+	if state.newestName == "" {
+		return nil
+	}
+
+	return clients.GetValkeyClient().SetCursor(ctx, REDDIT_SOURCE, topic.Topic, map[string]string{
+		"fullname":    state.newestName,
+		"created_utc": strconv.FormatInt(state.newest.Unix(), 10),
+	})
+}
+
+func loadRedditStreamState(ctx context.Context, topic string) *redditStreamState {
+	// Without a stored cursor only go back as far as the search mode would
+	state := &redditStreamState{
+		lastCreated: time.Now().Add(-24 * time.Hour),
+	}
+
+	fields, err := clients.GetValkeyClient().GetCursor(ctx, REDDIT_SOURCE, topic)
+	if err != nil {
+		slog.Warn("Failed to load Reddit cursor, starting from the newest posts",
+			slog.String("topic", topic),
+			slog.String("error", err.Error()))
+		return state
+	}
+
+	state.lastName = fields["fullname"]
+	if createdUTC, err := strconv.ParseInt(fields["created_utc"], 10, 64); err == nil {
+		state.lastCreated = time.Unix(createdUTC, 0)
+	}
+	state.newest = state.lastCreated
+
+	return state
+}
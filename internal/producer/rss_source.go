@@ -0,0 +1,109 @@
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+)
+
+const RSS_SOURCE = "rss"
+
+// RSSSource polls a fixed list of RSS/Atom feeds and matches their entries
+// against each topic. Feeds are downloaded at most once per refresh interval
+// and shared by every topic in the cycle.
+type RSSSource struct {
+	client   *clients.RSSClient
+	feeds    []string
+	interval time.Duration
+
+	mu        sync.Mutex
+	items     []models.FeedItem
+	fetchedAt time.Time
+}
+
+func NewRSSSource() *RSSSource {
+	var feeds []string
+	for _, feed := range strings.Split(getEnv("RSS_FEEDS", ""), ",") {
+		if feed = strings.TrimSpace(feed); feed != "" {
+			feeds = append(feeds, feed)
+		}
+	}
+
+	return &RSSSource{
+		client:   clients.GetRSSClient(),
+		feeds:    feeds,
+		interval: time.Duration(getEnvInt("RSS_REFRESH_INTERVAL", 300)) * time.Second,
+	}
+}
+
+func (rs *RSSSource) Name() string {
+	return RSS_SOURCE
+}
+
+func (rs *RSSSource) Fetch(ctx context.Context, topic models.Topic, cursor string) ([]models.RawContent, string, error) {
+	keywords := topicKeywords(topic.Topic)
+
+	var contents []models.RawContent
+	for _, item := range rs.feedItems(ctx) {
+		if !matchesTopic(item.Title+" "+item.Description, keywords) {
+			continue
+		}
+		contents = append(contents, feedItemToRaw(topic.Topic, item))
+	}
+
+	return contents, "", nil
+}
+
+// feedItems returns the entries of every configured feed, refreshing them
+// once the cached copy is older than the refresh interval
+func (rs *RSSSource) feedItems(ctx context.Context) []models.FeedItem {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if time.Since(rs.fetchedAt) < rs.interval {
+		return rs.items
+	}
+
+	var items []models.FeedItem
+	for _, feed := range rs.feeds {
+		feedItems, err := rs.client.FetchFeed(ctx, feed)
+		if err != nil {
+			slog.Warn("Failed to fetch feed",
+				slog.String("feed", feed),
+				slog.String("error", err.Error()))
+			continue
+		}
+		items = append(items, feedItems...)
+	}
+
+	slog.Info("Refreshed RSS feeds",
+		slog.Int("feeds", len(rs.feeds)),
+		slog.Int("items", len(items)))
+
+	rs.items = items
+	rs.fetchedAt = time.Now()
+	return rs.items
+}
+
+func feedItemToRaw(topic string, item models.FeedItem) models.RawContent {
+	source := RSS_SOURCE
+	return models.RawContent{
+		ContentID: rawcontent.ContentID(topic, source, item.GUID),
+		Source:    source,
+		Topic:     topic,
+		Text:      rawcontent.ComposeText(rawcontent.COMPOSITION_TITLE_BODY, item.Title, item.Description),
+		Metadata: models.ContentMetadata{
+			Author:          item.Author,
+			Timestamp:       item.PublishedAt,
+			PostID:          item.GUID,
+			URL:             item.Link,
+			TextComposition: string(rawcontent.COMPOSITION_TITLE_BODY),
+		},
+	}
+}
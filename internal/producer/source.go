@@ -0,0 +1,55 @@
+package producer
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// ContentSource is a content outlet the producer can pull from. Fetch returns
+// the content found for a topic along with the cursor for the next page, an
+// empty cursor means there is nothing left to fetch for this cycle.
+type ContentSource interface {
+	Name() string
+	Fetch(ctx context.Context, topic models.Topic, cursor string) ([]models.RawContent, string, error)
+}
+
+// CheckpointedSource is implemented by sources that persist where they left off
+// for a topic. Checkpoint is called once every page for the topic has been
+// processed, so a restart never skips content that wasn't published.
+type CheckpointedSource interface {
+	ContentSource
+	Checkpoint(ctx context.Context, topic models.Topic) error
+}
+
+// ThrottledSource is implemented by sources that are paced by a rate limiter,
+// ThrottledTime is the total time spent waiting on it
+type ThrottledSource interface {
+	ContentSource
+	ThrottledTime() time.Duration
+}
+
+var sourceRegistry = make(map[string]ContentSource)
+
+// RegisterSource adds a content source to the producers fetch cycle
+func RegisterSource(source ContentSource) {
+	sourceRegistry[source.Name()] = source
+}
+
+// registeredSources returns the registered sources sorted by name so every
+// cycle walks them in the same order
+func registeredSources() []ContentSource {
+	names := make([]string, 0, len(sourceRegistry))
+	for name := range sourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sources := make([]ContentSource, 0, len(names))
+	for _, name := range names {
+		sources = append(sources, sourceRegistry[name])
+	}
+	return sources
+}
@@ -0,0 +1,70 @@
+package producer
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CycleStats collects what a single fetch cycle did across every worker
+type CycleStats struct {
+	TopicsDone   atomic.Int64
+	TopicsFailed atomic.Int64
+	Published    atomic.Int64
+	Throttled    time.Duration
+	Started      time.Time
+
+	dropsMu sync.Mutex
+	drops   map[string]int64
+}
+
+func newCycleStats() *CycleStats {
+	return &CycleStats{
+		Started: time.Now(),
+		drops:   make(map[string]int64),
+	}
+}
+
+// RecordDrop counts content dropped by a quality rule
+func (cs *CycleStats) RecordDrop(rule string) {
+	cs.dropsMu.Lock()
+	defer cs.dropsMu.Unlock()
+	cs.drops[rule]++
+}
+
+func (cs *CycleStats) Log() {
+	slog.Info("Fetch cycle stats",
+		slog.Int64("topics_done", cs.TopicsDone.Load()),
+		slog.Int64("topics_failed", cs.TopicsFailed.Load()),
+		slog.Int64("published", cs.Published.Load()),
+		slog.Duration("throttled", cs.Throttled),
+		slog.Duration("elapsed", time.Since(cs.Started)))
+
+	cs.dropsMu.Lock()
+	defer cs.dropsMu.Unlock()
+
+	rules := make([]string, 0, len(cs.drops))
+	for rule := range cs.drops {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	attrs := make([]any, 0, len(rules))
+	for _, rule := range rules {
+		attrs = append(attrs, slog.Int64(rule, cs.drops[rule]))
+	}
+	slog.Info("Fetch cycle quality filter drops", attrs...)
+}
+
+// throttledTime sums the time every rate limited source has spent waiting
+func throttledTime(sources []ContentSource) time.Duration {
+	var total time.Duration
+	for _, source := range sources {
+		if throttled, ok := source.(ThrottledSource); ok {
+			total += throttled.ThrottledTime()
+		}
+	}
+	return total
+}
@@ -0,0 +1,133 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
+)
+
+// DISCOVERY_RETRY_AFTER is how long a topic whose discovery failed keeps the
+// category defaults before discovery is tried again
+const DISCOVERY_RETRY_AFTER = 5 * time.Minute
+
+// resolvedSubreddits remembers the subreddit list of every topic in memory, so
+// paging through a topic doesn't go back to Valkey or Reddit for every page
+type resolvedSubreddits struct {
+	entries map[string]resolvedSubredditsEntry
+	mu      sync.Mutex
+}
+
+type resolvedSubredditsEntry struct {
+	subreddits string
+	expires    time.Time
+}
+
+func newResolvedSubreddits() *resolvedSubreddits {
+	return &resolvedSubreddits{
+		entries: make(map[string]resolvedSubredditsEntry),
+	}
+}
+
+func (r *resolvedSubreddits) get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(r.entries, key)
+		return "", false
+	}
+	return entry.subreddits, true
+}
+
+func (r *resolvedSubreddits) set(key string, subreddits string, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[key] = resolvedSubredditsEntry{subreddits: subreddits, expires: time.Now().Add(ttl)}
+}
+
+// discoverSubreddits returns the subreddits Reddit finds most relevant to a
+// topic, false when the search failed. Results are cached per topic in Valkey so discovery only costs one
+// request per topic every REDDIT_DISCOVERY_TTL seconds.
+func (rs *RedditSource) discoverSubreddits(ctx context.Context, topic models.Topic) ([]string, bool) {
+	if rs.discoveryLimit <= 0 {
+		return nil, true
+	}
+
+	cacheKey := fmt.Sprintf("%s:subreddits:%s", REDDIT_SOURCE, topic.Topic)
+	if cached, ok := clients.GetValkeyClient().GetCached(ctx, cacheKey); ok {
+		if cached == "" {
+			return nil, true
+		}
+		return strings.Split(cached, "+"), true
+	}
+
+	subreddits, err := rs.client.SearchSubreddits(ctx, topic.Topic, rs.discoveryLimit)
+	if err != nil {
+		slog.Warn("Subreddit discovery failed, using category defaults",
+			slog.String("topic", topic.Topic),
+			slog.String("error", err.Error()))
+		return nil, false
+	}
+
+	// An empty result is cached too so topics without a match aren't searched every tick
+	if err := clients.GetValkeyClient().SetCached(ctx, cacheKey, strings.Join(subreddits, "+"), rs.discoveryTTL); err != nil {
+		slog.Warn("Failed to cache discovered subreddits",
+			slog.String("topic", topic.Topic),
+			slog.String("error", err.Error()))
+	}
+
+	slog.Info("Discovered subreddits for topic",
+		slog.String("topic", topic.Topic),
+		slog.String("subreddits", strings.Join(subreddits, "+")))
+
+	return subreddits, true
+}
+
+// subredditsForTopic merges the category defaults with the discovered
+// subreddits into the r/a+b+c form the search endpoint expects. The list is
+// resolved once per topic and reused for every page until discovery expires.
+func (rs *RedditSource) subredditsForTopic(ctx context.Context, topic models.Topic) string {
+	key := topic.Category + ":" + topic.Topic
+	if subreddits, ok := rs.resolved.get(key); ok {
+		return subreddits
+	}
+
+	subreddits, discovered := rs.resolveSubreddits(ctx, topic)
+	// a failed discovery is tried again soon rather than after the full TTL
+	ttl := rs.discoveryTTL
+	if !discovered {
+		ttl = min(ttl, DISCOVERY_RETRY_AFTER)
+	}
+	rs.resolved.set(key, subreddits, ttl)
+	return subreddits
+}
+
+func (rs *RedditSource) resolveSubreddits(ctx context.Context, topic models.Topic) (string, bool) {
+	var merged []string
+	seen := make(map[string]struct{})
+
+	add := func(subreddits []string) {
+		for _, subreddit := range subreddits {
+			key := strings.ToLower(subreddit)
+			if _, exists := seen[key]; exists || subreddit == "" {
+				continue
+			}
+			seen[key] = struct{}{}
+			merged = append(merged, subreddit)
+		}
+	}
+
+	add(taxonomy.Get().Subreddits(topic.Category))
+	discovered, ok := rs.discoverSubreddits(ctx, topic)
+	add(discovered)
+
+	return strings.Join(merged, "+"), ok
+}
@@ -0,0 +1,64 @@
+package producer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TOPIC_MATCH_RATIO is the share of a topics keywords that must appear in a
+// piece of text for it to count as being about that topic
+const TOPIC_MATCH_RATIO = 0.6
+
+var stopwords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "with": {}, "from": {}, "that": {}, "this": {},
+	"are": {}, "was": {}, "were": {}, "has": {}, "have": {}, "had": {}, "its": {},
+	"into": {}, "over": {}, "after": {}, "about": {}, "amid": {}, "new": {}, "says": {},
+	"will": {}, "not": {}, "but": {}, "his": {}, "her": {}, "their": {}, "out": {},
+}
+
+// topicKeywords splits a topic query into the lower cased words worth matching on
+func topicKeywords(topic string) []string {
+	var keywords []string
+	seen := make(map[string]struct{})
+	for _, word := range tokenize(topic) {
+		if len([]rune(word)) < 3 {
+			continue
+		}
+		if _, stop := stopwords[word]; stop {
+			continue
+		}
+		if _, exists := seen[word]; exists {
+			continue
+		}
+		seen[word] = struct{}{}
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
+
+// matchesTopic reports whether enough of the keywords appear in text
+func matchesTopic(text string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+
+	words := make(map[string]struct{})
+	for _, word := range tokenize(text) {
+		words[word] = struct{}{}
+	}
+
+	matched := 0
+	for _, keyword := range keywords {
+		if _, ok := words[keyword]; ok {
+			matched++
+		}
+	}
+
+	return float64(matched)/float64(len(keywords)) >= TOPIC_MATCH_RATIO
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,43 @@
+package producer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+)
+
+// PrepareRawContent checks the fields the consumers rely on and fills in a
+// ContentID when one is missing, for content that didn't come from a source
+func PrepareRawContent(c *models.RawContent) error {
+	c.Source = strings.TrimSpace(c.Source)
+	c.Topic = strings.TrimSpace(c.Topic)
+
+	var errs []error
+	if c.Source == "" {
+		errs = append(errs, errors.New("source is required"))
+	}
+	if c.Topic == "" {
+		errs = append(errs, errors.New("topic is required"))
+	}
+	if strings.TrimSpace(c.Text) == "" {
+		errs = append(errs, errors.New("text is required"))
+	}
+	if c.ContentID == "" && c.Metadata.PostID == "" {
+		errs = append(errs, errors.New("content_id or metadata.post_id is required"))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if c.ContentID == "" {
+		c.ContentID = rawcontent.ContentID(c.Topic, c.Source, c.Metadata.PostID)
+	}
+	return nil
+}
+
+// RedditPostToRaw converts a Reddit post the same way the Reddit source does
+func RedditPostToRaw(p models.RedditPost) models.RawContent {
+	return redditPostToRaw(p, rawcontent.ParseTextComposition(getEnv("REDDIT_TEXT_COMPOSITION", "")))
+}
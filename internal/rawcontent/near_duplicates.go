@@ -0,0 +1,131 @@
+package rawcontent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/fingerprint"
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// What happens to content that is a near-duplicate of something already published
+const (
+	NEAR_DUPLICATE_OFF  = "off"  // only exact duplicates are caught
+	NEAR_DUPLICATE_MARK = "mark" // published with duplicate_of set and a reduced weight
+	NEAR_DUPLICATE_SKIP = "skip" // not published at all
+)
+
+type nearDuplicateConfig struct {
+	mode        string
+	maxDistance int
+	minWords    int
+	weight      float64
+	ttl         time.Duration
+}
+
+var (
+	nearDuplicateOnce     sync.Once
+	nearDuplicateSettings nearDuplicateConfig
+)
+
+func nearDuplicates() nearDuplicateConfig {
+	nearDuplicateOnce.Do(func() {
+		mode := os.Getenv("NEAR_DUPLICATE_MODE")
+		if mode == "" {
+			mode = NEAR_DUPLICATE_MARK
+		}
+		if mode != NEAR_DUPLICATE_OFF && mode != NEAR_DUPLICATE_MARK && mode != NEAR_DUPLICATE_SKIP {
+			slog.Warn("Unknown near-duplicate mode, falling back to mark",
+				slog.String("mode", mode))
+			mode = NEAR_DUPLICATE_MARK
+		}
+
+		weight := 0.25
+		if parsed, err := strconv.ParseFloat(os.Getenv("NEAR_DUPLICATE_WEIGHT"), 64); err == nil {
+			weight = parsed
+		}
+
+		nearDuplicateSettings = nearDuplicateConfig{
+			mode: mode,
+			// the LSH bands only guarantee a shared bucket below BANDS bits
+			maxDistance: min(envInt("NEAR_DUPLICATE_MAX_DISTANCE", 6), fingerprint.BANDS-1),
+			minWords:    envInt("NEAR_DUPLICATE_MIN_WORDS", 8),
+			weight:      weight,
+			ttl:         time.Duration(envInt("NEAR_DUPLICATE_TTL", 48*60*60)) * time.Second,
+		}
+	})
+	return nearDuplicateSettings
+}
+
+// findNearDuplicate fingerprints the content and looks for an earlier content
+// of the same topic within maxDistance bits, texts too short to fingerprint
+// reliably are never treated as duplicates
+func findNearDuplicate(ctx context.Context, content models.RawContent, cfg nearDuplicateConfig) (uint64, string, bool) {
+	words := fingerprint.Normalize(content.Text)
+	if len(words) < cfg.minWords {
+		return 0, "", false
+	}
+
+	hash := fingerprint.SimHash(words)
+	candidates, err := clients.GetValkeyClient().GetFingerprintBuckets(ctx, fingerprintBucketKeys(content.Topic, hash))
+	if err != nil {
+		slog.Warn("Failed to look up near-duplicates",
+			slog.String("content_id", content.ContentID),
+			slog.String("error", err.Error()))
+		return hash, "", true
+	}
+
+	for contentID, stored := range candidates {
+		if contentID == content.ContentID {
+			continue
+		}
+		other, err := strconv.ParseUint(stored, 16, 64)
+		if err != nil {
+			continue
+		}
+		if fingerprint.Distance(hash, other) <= cfg.maxDistance {
+			return hash, contentID, true
+		}
+	}
+	return hash, "", true
+}
+
+// registerFingerprint stores the fingerprint of published content so later
+// content can be matched against it
+func registerFingerprint(ctx context.Context, content models.RawContent, hash uint64, cfg nearDuplicateConfig) {
+	err := clients.GetValkeyClient().AddToFingerprintBuckets(ctx,
+		fingerprintBucketKeys(content.Topic, hash),
+		content.ContentID,
+		strconv.FormatUint(hash, 16),
+		cfg.ttl)
+	if err != nil {
+		slog.Warn("Failed to store content fingerprint",
+			slog.String("content_id", content.ContentID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// fingerprintBucketKeys are the LSH buckets of a fingerprint, scoped to the
+// topic since results are aggregated per topic
+func fingerprintBucketKeys(topic string, hash uint64) []string {
+	bands := fingerprint.Bands(hash)
+	keys := make([]string, 0, len(bands))
+	for i, band := range bands {
+		keys = append(keys, fmt.Sprintf("fingerprint:%s:%d:%02x", topic, i, band))
+	}
+	return keys
+}
+
+func envInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
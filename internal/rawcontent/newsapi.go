@@ -0,0 +1,46 @@
+package rawcontent
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+const NEWSAPI_SOURCE = "newsapi"
+
+// NewsAPI truncates content to 200 characters and appends "… [+1234 chars]"
+var newsAPITruncation = regexp.MustCompile(`\s*(…|\.\.\.)?\s*\[\+\d+ chars\]\s*$`)
+
+// NewsArticleToRaw converts a NewsAPI article into RawContent for a topic, so
+// the press coverage of a topic is analyzed next to the discussion about it
+func NewsArticleToRaw(topic string, article models.NewsAPIArticles) models.RawContent {
+	source := NEWSAPI_SOURCE
+
+	description := strings.TrimSpace(article.Description)
+	content := strings.TrimSpace(newsAPITruncation.ReplaceAllString(article.Content, ""))
+
+	body := description
+	switch {
+	case content == "":
+	case description == "" || strings.HasPrefix(content, description):
+		body = content
+	case !strings.HasPrefix(description, content):
+		body = description + "\n\n" + content
+	}
+
+	return models.RawContent{
+		ContentID: ContentID(topic, source, article.URL),
+		Source:    source,
+		Topic:     topic,
+		Text:      ComposeText(COMPOSITION_TITLE_BODY, article.Title, body),
+		Metadata: models.ContentMetadata{
+			Author:          article.Author,
+			Timestamp:       article.PublishedAt,
+			PostID:          article.URL,
+			URL:             article.URL,
+			Publisher:       article.Source.Name,
+			TextComposition: string(COMPOSITION_TITLE_BODY),
+		},
+	}
+}
@@ -0,0 +1,95 @@
+// Package rawcontent builds and publishes the RawContent records analyzed by
+// the consumers. It is shared by everything that writes to the raw content
+// topic, so they all dedupe against the same Valkey sets and fingerprints.
+package rawcontent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/spacesedan/sentiflow/internal/clients"
+	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// ErrDuplicateContent is returned by Publish for content that has already
+// been published
+var ErrDuplicateContent = errors.New("content has already been processed")
+
+// ContentID derives a stable content ID from the topic, the source and the
+// sources own ID for the item
+func ContentID(topic, source, id string) string {
+	raw := fmt.Sprintf("%s:%s:%s", topic, source, id)
+	hash := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(hash[:])
+}
+
+// Publish dedupes a single piece of content through Valkey and publishes it
+// to the raw content topic
+func Publish(ctx context.Context, content models.RawContent) error {
+	dedupeKey := contentDedupeKey(content)
+
+	if IsProcessed(ctx, content) {
+		return ErrDuplicateContent
+	}
+
+	// Crossposts and copy-pasted text get new IDs, so they are matched by
+	// their SimHash fingerprint against what was already published
+	var hash uint64
+	var fingerprinted bool
+	cfg := nearDuplicates()
+	if cfg.mode != NEAR_DUPLICATE_OFF {
+		var duplicateOf string
+		hash, duplicateOf, fingerprinted = findNearDuplicate(ctx, content, cfg)
+		if duplicateOf != "" {
+			if cfg.mode == NEAR_DUPLICATE_SKIP {
+				markProcessed(ctx, content, dedupeKey)
+				return fmt.Errorf("%w: near-duplicate of %s", ErrDuplicateContent, duplicateOf)
+			}
+			content.Metadata.DuplicateOf = duplicateOf
+			content.Metadata.Weight = cfg.weight
+			fingerprinted = false
+		}
+	}
+
+	if err := kafka_client.PublishToKafka(ctx, kafka_client.KAFKA_TOPIC_RAW_CONTENT, content); err != nil {
+		return err
+	}
+
+	markProcessed(ctx, content, dedupeKey)
+
+	// only originals are registered so duplicates always point at the first copy
+	if fingerprinted {
+		registerFingerprint(ctx, content, hash, cfg)
+	}
+	return nil
+}
+
+// IsProcessed reports whether content has already been published, or dropped
+// as a near-duplicate, under the same key Publish dedupes by
+func IsProcessed(ctx context.Context, content models.RawContent) bool {
+	return clients.GetValkeyClient().IsPostProcessed(ctx, content.Source, contentDedupeKey(content))
+}
+
+func markProcessed(ctx context.Context, content models.RawContent, dedupeKey string) {
+	if err := clients.GetValkeyClient().MarkProcessed(ctx, content.Source, dedupeKey); err != nil {
+		slog.Warn("Error marking post as processed",
+			slog.String("source", content.Source),
+			slog.String("post_id", content.Metadata.PostID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// contentDedupeKey keys content by topic and the sources own ID, falling back
+// to the content ID for content that has none
+func contentDedupeKey(content models.RawContent) string {
+	id := content.Metadata.PostID
+	if id == "" {
+		id = content.ContentID
+	}
+	return fmt.Sprintf("%s:%s", content.Topic, id)
+}
@@ -0,0 +1,54 @@
+package rawcontent
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// TextComposition controls how a posts title and body are combined into the
+// text that gets analyzed
+type TextComposition string
+
+const (
+	COMPOSITION_TITLE         TextComposition = "title"         // title only
+	COMPOSITION_TITLE_BODY    TextComposition = "title_body"    // title followed by the body
+	COMPOSITION_BODY_FALLBACK TextComposition = "body_fallback" // body, falling back to the title for link posts
+)
+
+func ParseTextComposition(value string) TextComposition {
+	switch composition := TextComposition(value); composition {
+	case COMPOSITION_TITLE, COMPOSITION_TITLE_BODY, COMPOSITION_BODY_FALLBACK:
+		return composition
+	case "":
+		return COMPOSITION_BODY_FALLBACK
+	default:
+		slog.Warn("Unknown text composition, falling back to default",
+			slog.String("composition", value),
+			slog.String("default", string(COMPOSITION_BODY_FALLBACK)))
+		return COMPOSITION_BODY_FALLBACK
+	}
+}
+
+// ComposeText builds the text to analyze from a title and body
+func ComposeText(composition TextComposition, title, body string) string {
+	title = strings.TrimSpace(title)
+	body = strings.TrimSpace(body)
+
+	switch composition {
+	case COMPOSITION_TITLE:
+		return title
+	case COMPOSITION_TITLE_BODY:
+		if body == "" {
+			return title
+		}
+		if title == "" {
+			return body
+		}
+		return title + "\n\n" + body
+	default:
+		if body == "" {
+			return title
+		}
+		return body
+	}
+}
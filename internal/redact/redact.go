@@ -0,0 +1,160 @@
+// Package redact removes personal information from text before it leaves the
+// pipeline. Detectors are regex based, card numbers are confirmed with the
+// Luhn checksum and phone numbers by their digit count to keep false positives
+// on scores, dates and IDs down.
+package redact
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Detector names, also the keys redaction counts are reported under
+const (
+	EMAIL       = "email"
+	CREDIT_CARD = "credit_card"
+	IP_ADDRESS  = "ip_address"
+	PHONE       = "phone"
+	USERNAME    = "username"
+)
+
+// Detector finds one kind of personal information and replaces it
+type Detector struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+	// Valid confirms a match, matches that fail are left alone
+	Valid func(match string) bool
+}
+
+// Counts is the number of redactions made per detector
+type Counts map[string]int
+
+// Detectors run in order, card numbers go before phone numbers so long digit
+// runs are claimed by the stricter check first
+var Detectors = []Detector{
+	{
+		Name:        EMAIL,
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replacement: "[EMAIL]",
+	},
+	{
+		Name:        CREDIT_CARD,
+		Pattern:     regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Replacement: "[CARD]",
+		Valid:       luhnValid,
+	},
+	{
+		Name: IP_ADDRESS,
+		Pattern: regexp.MustCompile(
+			`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b` +
+				`|\b(?:[0-9A-Fa-f]{1,4}:){7}[0-9A-Fa-f]{1,4}\b` +
+				`|\b(?:[0-9A-Fa-f]{1,4}:){1,6}:(?:[0-9A-Fa-f]{1,4}(?::[0-9A-Fa-f]{1,4}){0,5})?\b`),
+		Replacement: "[IP]",
+	},
+	{
+		Name:        PHONE,
+		Pattern:     regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?|\b\d{2,4}[ .-])\d{3,4}[ .-]?\d{3,4}\b`),
+		Replacement: "[PHONE]",
+		Valid:       phoneValid,
+	},
+	{
+		Name:        USERNAME,
+		Pattern:     regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9_/])/?u/[A-Za-z0-9_-]{3,20}\b`),
+		Replacement: "u/[USER]",
+	},
+}
+
+// Redactor applies a set of detectors
+type Redactor struct {
+	detectors []Detector
+}
+
+// NewRedactor builds a redactor from REDACT_DETECTORS, a comma separated list
+// of detector names, every detector is used when it isn't set. REDACT_ENABLED
+// set to false turns redaction off altogether.
+func NewRedactor() *Redactor {
+	if os.Getenv("REDACT_ENABLED") == "false" {
+		return &Redactor{}
+	}
+
+	enabled := make(map[string]struct{})
+	for _, name := range strings.Split(os.Getenv("REDACT_DETECTORS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			enabled[name] = struct{}{}
+		}
+	}
+
+	var detectors []Detector
+	for _, detector := range Detectors {
+		if _, ok := enabled[detector.Name]; ok || len(enabled) == 0 {
+			detectors = append(detectors, detector)
+		}
+	}
+	return &Redactor{detectors: detectors}
+}
+
+// Redact replaces personal information in text and counts what was replaced
+func (r *Redactor) Redact(text string) (string, Counts) {
+	counts := make(Counts)
+	for _, detector := range r.detectors {
+		text = detector.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			value, prefix := match, ""
+			if detector.Name == USERNAME {
+				// the pattern consumes the character before the mention
+				i := strings.Index(strings.ToLower(match), "u/")
+				if i > 0 && match[i-1] == '/' {
+					i--
+				}
+				prefix, value = match[:i], match[i:]
+			}
+
+			if detector.Valid != nil && !detector.Valid(value) {
+				return match
+			}
+			counts[detector.Name]++
+			return prefix + detector.Replacement
+		})
+	}
+	return text, counts
+}
+
+// luhnValid checks the digits of a card number against the Luhn checksum
+func luhnValid(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// phoneValid accepts numbers with as many digits as a phone number can have
+func phoneValid(match string) bool {
+	digits := onlyDigits(match)
+	return len(digits) >= 10 && len(digits) <= 15
+}
+
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
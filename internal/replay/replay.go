@@ -0,0 +1,245 @@
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacesedan/sentiflow/internal/clients/kafka_client"
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/producer"
+)
+
+// Record formats a replay file can hold
+const (
+	FORMAT_AUTO   = "auto"
+	FORMAT_RAW    = "raw"
+	FORMAT_REDDIT = "reddit"
+)
+
+const MAX_LINE_SIZE = 16 * 1024 * 1024
+
+type Options struct {
+	Format          string
+	Rate            float64 // messages per second, 0 means unthrottled
+	CheckpointPath  string
+	CheckpointEvery int
+	TimestampsNow   bool
+	TimeShift       time.Duration
+	DryRun          bool
+}
+
+type Stats struct {
+	Published int
+	Skipped   int
+	Invalid   int
+}
+
+// File replays a JSONL (optionally gzipped) file into the raw content topic,
+// resuming after the last line recorded in the checkpoint file
+func File(ctx context.Context, path string, opts Options) (Stats, error) {
+	var stats Stats
+
+	f, err := os.Open(path)
+	if err != nil {
+		return stats, fmt.Errorf("[Replay] Failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader, err := openReader(f)
+	if err != nil {
+		return stats, fmt.Errorf("[Replay] Failed to read %s: %w", path, err)
+	}
+
+	resumeAfter := readCheckpoint(opts.CheckpointPath)
+	if resumeAfter > 0 {
+		slog.Info("[Replay] Resuming from checkpoint",
+			slog.String("file", path),
+			slog.Int("line", resumeAfter))
+	}
+
+	var throttle <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 1024*1024), MAX_LINE_SIZE)
+
+	line := 0
+	lastDone := resumeAfter
+	defer func() {
+		writeCheckpoint(opts.CheckpointPath, lastDone)
+	}()
+
+	for scanner.Scan() {
+		line++
+		if line <= resumeAfter {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		default:
+		}
+
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			stats.Skipped++
+			lastDone = line
+			continue
+		}
+
+		content, err := decodeLine([]byte(raw), opts.Format)
+		if err == nil {
+			err = producer.PrepareRawContent(&content)
+		}
+		if err != nil {
+			stats.Invalid++
+			lastDone = line
+			slog.Warn("[Replay] Skipping invalid record",
+				slog.String("file", path),
+				slog.Int("line", line),
+				slog.String("error", err.Error()))
+			continue
+		}
+		rewriteTimestamp(&content, opts)
+
+		if throttle != nil {
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-throttle:
+			}
+		}
+
+		if !opts.DryRun {
+			if err := kafka_client.PublishToKafka(ctx, kafka_client.KAFKA_TOPIC_RAW_CONTENT, content); err != nil {
+				// Stop here so the checkpoint points at the last line that made it
+				return stats, fmt.Errorf("[Replay] Failed to publish line %d: %w", line, err)
+			}
+		}
+		stats.Published++
+		lastDone = line
+
+		if opts.CheckpointEvery > 0 && stats.Published%opts.CheckpointEvery == 0 {
+			writeCheckpoint(opts.CheckpointPath, lastDone)
+			slog.Info("[Replay] Progress",
+				slog.String("file", path),
+				slog.Int("line", line),
+				slog.Int("published", stats.Published))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("[Replay] Failed reading %s at line %d: %w", path, line+1, err)
+	}
+	return stats, nil
+}
+
+// openReader transparently decompresses gzip input, detected by its magic bytes
+func openReader(f *os.File) (io.Reader, error) {
+	buffered := bufio.NewReader(f)
+	magic, err := buffered.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
+
+// recordProbe is used to tell RawContent and RedditPost records apart
+type recordProbe struct {
+	ContentID   *string `json:"content_id"`
+	Text        *string `json:"text"`
+	PostContent *string `json:"post_content"`
+	PostTitle   *string `json:"post_title"`
+}
+
+func decodeLine(data []byte, format string) (models.RawContent, error) {
+	if format == FORMAT_AUTO {
+		var probe recordProbe
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return models.RawContent{}, err
+		}
+		switch {
+		case probe.ContentID != nil || probe.Text != nil:
+			format = FORMAT_RAW
+		case probe.PostContent != nil || probe.PostTitle != nil:
+			format = FORMAT_REDDIT
+		default:
+			return models.RawContent{}, errors.New("unrecognized record, expected RawContent or RedditPost")
+		}
+	}
+
+	switch format {
+	case FORMAT_RAW:
+		var content models.RawContent
+		if err := json.Unmarshal(data, &content); err != nil {
+			return content, err
+		}
+		return content, nil
+	case FORMAT_REDDIT:
+		var post models.RedditPost
+		if err := json.Unmarshal(data, &post); err != nil {
+			return models.RawContent{}, err
+		}
+		if post.PostID == "" {
+			return models.RawContent{}, errors.New("reddit post is missing an id")
+		}
+		return producer.RedditPostToRaw(post), nil
+	default:
+		return models.RawContent{}, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func rewriteTimestamp(content *models.RawContent, opts Options) {
+	if opts.TimestampsNow || content.Metadata.Timestamp.IsZero() {
+		content.Metadata.Timestamp = time.Now()
+		return
+	}
+	content.Metadata.Timestamp = content.Metadata.Timestamp.Add(opts.TimeShift)
+}
+
+func readCheckpoint(path string) int {
+	if path == "" {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	line, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		slog.Warn("[Replay] Ignoring unreadable checkpoint",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return 0
+	}
+	return line
+}
+
+func writeCheckpoint(path string, line int) {
+	if path == "" {
+		return
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(line)+"\n"), 0o644); err != nil {
+		slog.Warn("[Replay] Failed to write checkpoint",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+	}
+}
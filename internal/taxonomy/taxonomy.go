@@ -0,0 +1,185 @@
+package taxonomy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_TAXONOMY_FILE = "config/taxonomy.json"
+
+// Taxonomy is the single list of categories shared by the producer, the topic
+// generator and the NewsAPI client
+type Taxonomy struct {
+	Categories []Category `json:"categories"`
+}
+
+type Category struct {
+	Name              string   `json:"name"`
+	Subcategories     []string `json:"subcategories,omitempty"`
+	Subreddits        []string `json:"subreddits"`
+	NewsAPICategories []string `json:"newsapi_categories"`
+}
+
+var current atomic.Pointer[Taxonomy]
+
+// Init loads the taxonomy from TAXONOMY_FILE, falling back to the default path
+func Init() error {
+	t, err := Load(taxonomyFile())
+	if err != nil {
+		return err
+	}
+	current.Store(t)
+	slog.Info("[Taxonomy] Loaded taxonomy",
+		slog.Int("categories", len(t.Categories)))
+	return nil
+}
+
+// Get returns the currently loaded taxonomy
+func Get() *Taxonomy {
+	t := current.Load()
+	if t == nil {
+		panic("[Taxonomy] Error: taxonomy is not initialized")
+	}
+	return t
+}
+
+// Load reads and validates a taxonomy file
+func Load(path string) (*Taxonomy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[Taxonomy] Failed to read %s: %w", path, err)
+	}
+
+	var t Taxonomy
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("[Taxonomy] Failed to parse %s: %w", path, err)
+	}
+
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Watch polls the taxonomy file and swaps in the new version whenever it
+// changes. A file that fails to load is logged and the previous taxonomy is kept.
+func Watch(ctx context.Context) {
+	path := taxonomyFile()
+	interval := 30 * time.Second
+	if seconds, err := strconv.Atoi(os.Getenv("TAXONOMY_RELOAD_INTERVAL")); err == nil && seconds > 0 {
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	var lastModified time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				slog.Warn("[Taxonomy] Failed to stat taxonomy file",
+					slog.String("path", path),
+					slog.String("error", err.Error()))
+				continue
+			}
+			if !info.ModTime().After(lastModified) {
+				continue
+			}
+			lastModified = info.ModTime()
+
+			t, err := Load(path)
+			if err != nil {
+				slog.Error("[Taxonomy] Reload failed, keeping previous taxonomy",
+					slog.String("error", err.Error()))
+				continue
+			}
+			current.Store(t)
+			slog.Info("[Taxonomy] Reloaded taxonomy",
+				slog.Int("categories", len(t.Categories)))
+		}
+	}
+}
+
+// CategoryNames returns the names of every category in file order
+func (t *Taxonomy) CategoryNames() []string {
+	names := make([]string, 0, len(t.Categories))
+	for _, c := range t.Categories {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Category looks up a category by name
+func (t *Taxonomy) Category(name string) (Category, bool) {
+	for _, c := range t.Categories {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Category{}, false
+}
+
+// Subreddits returns the default subreddits for a category
+func (t *Taxonomy) Subreddits(category string) []string {
+	c, ok := t.Category(category)
+	if !ok {
+		return nil
+	}
+	return c.Subreddits
+}
+
+// NewsAPICategories returns every NewsAPI category referenced by the taxonomy
+func (t *Taxonomy) NewsAPICategories() []string {
+	seen := make(map[string]struct{})
+	var categories []string
+	for _, c := range t.Categories {
+		for _, newsCategory := range c.NewsAPICategories {
+			if _, exists := seen[newsCategory]; exists {
+				continue
+			}
+			seen[newsCategory] = struct{}{}
+			categories = append(categories, newsCategory)
+		}
+	}
+	return categories
+}
+
+func (t *Taxonomy) validate() error {
+	if len(t.Categories) == 0 {
+		return errors.New("[Taxonomy] Taxonomy has no categories")
+	}
+
+	seen := make(map[string]struct{}, len(t.Categories))
+	for _, c := range t.Categories {
+		if c.Name == "" {
+			return errors.New("[Taxonomy] Category is missing a name")
+		}
+		if _, exists := seen[c.Name]; exists {
+			return fmt.Errorf("[Taxonomy] Duplicate category %q", c.Name)
+		}
+		seen[c.Name] = struct{}{}
+	}
+	return nil
+}
+
+func taxonomyFile() string {
+	if path := os.Getenv("TAXONOMY_FILE"); path != "" {
+		return path
+	}
+	return DEFAULT_TAXONOMY_FILE
+}
@@ -0,0 +1,259 @@
+package topicgeneration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spacesedan/sentiflow/internal/fingerprint"
+)
+
+// Supported embedding providers, selected with TOPIC_EMBEDDING_PROVIDER
+const (
+	EMBEDDING_PROVIDER_LOCAL             = "local"
+	EMBEDDING_PROVIDER_OPENAI            = "openai"
+	EMBEDDING_PROVIDER_OPENAI_COMPATIBLE = "openai-compatible"
+	EMBEDDING_PROVIDER_OFF               = "off"
+)
+
+const (
+	DEFAULT_EMBEDDING_MODEL            = string(openai.SmallEmbedding3)
+	DEFAULT_COMPATIBLE_EMBEDDING_MODEL = "nomic-embed-text"
+
+	// cosine similarity at which two topics are the same story. The local
+	// embedder only sees shared words, "Fed raises rates" and "Bank of England
+	// raises rates" look alike to it, so it only merges near copies.
+	DEFAULT_DEDUPE_THRESHOLD       = 0.8
+	DEFAULT_LOCAL_DEDUPE_THRESHOLD = 0.75
+
+	// texts sent per embeddings request
+	EMBEDDING_BATCH_SIZE = 100
+	// dimensions of the local hashed embeddings
+	LOCAL_EMBEDDING_DIMENSIONS = 512
+)
+
+// TopicEmbedder turns topics into vectors whose cosine similarity says how
+// close two topics are in meaning
+type TopicEmbedder interface {
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewTopicEmbedder builds the provider configured in env along with the
+// similarity threshold for it, a nil embedder means semantic dedupe is off.
+// Without a provider it uses openai when an OpenAI key is configured and is
+// off otherwise, the local embedder is too crude to merge topics unasked.
+func NewTopicEmbedder() (TopicEmbedder, float64, error) {
+	provider := os.Getenv("TOPIC_EMBEDDING_PROVIDER")
+	if provider == "" {
+		provider = EMBEDDING_PROVIDER_OFF
+		if os.Getenv("TOPIC_EMBEDDING_API_KEY") != "" || os.Getenv("OPENAI_API_KEY") != "" {
+			provider = EMBEDDING_PROVIDER_OPENAI
+		}
+	}
+
+	threshold := DEFAULT_DEDUPE_THRESHOLD
+	if provider == EMBEDDING_PROVIDER_LOCAL {
+		threshold = DEFAULT_LOCAL_DEDUPE_THRESHOLD
+	}
+	if configured, err := strconv.ParseFloat(os.Getenv("TOPIC_DEDUPE_THRESHOLD"), 64); err == nil && configured > 0 && configured <= 1 {
+		threshold = configured
+	}
+
+	model := os.Getenv("TOPIC_EMBEDDING_MODEL")
+	baseURL := os.Getenv("TOPIC_EMBEDDING_BASE_URL")
+	apiKey := os.Getenv("TOPIC_EMBEDDING_API_KEY")
+	if apiKey == "" {
+		apiKey = LoadTopicLLMConfig().APIKey
+	}
+
+	slog.Info("[TopicGenerator] Using topic embeddings",
+		slog.String("provider", provider),
+		slog.Float64("threshold", threshold))
+
+	switch provider {
+	case EMBEDDING_PROVIDER_OFF:
+		return nil, 0, nil
+
+	case EMBEDDING_PROVIDER_LOCAL:
+		return NewLocalEmbedder(), threshold, nil
+
+	case EMBEDDING_PROVIDER_OPENAI:
+		if apiKey == "" {
+			return nil, 0, errors.New("[TopicGenerator] Missing OPENAI_API_KEY for the openai embedding provider")
+		}
+		if model == "" {
+			model = DEFAULT_EMBEDDING_MODEL
+		}
+		clientConfig := openai.DefaultConfig(apiKey)
+		if baseURL != "" {
+			clientConfig.BaseURL = baseURL
+		}
+		return newOpenAIEmbedder(EMBEDDING_PROVIDER_OPENAI, clientConfig, model), threshold, nil
+
+	case EMBEDDING_PROVIDER_OPENAI_COMPATIBLE:
+		if model == "" {
+			model = DEFAULT_COMPATIBLE_EMBEDDING_MODEL
+		}
+		if baseURL == "" {
+			baseURL = DEFAULT_COMPATIBLE_BASEURL
+		}
+		clientConfig := openai.DefaultConfig(apiKey)
+		clientConfig.BaseURL = baseURL
+		return newOpenAIEmbedder(EMBEDDING_PROVIDER_OPENAI_COMPATIBLE, clientConfig, model), threshold, nil
+	}
+
+	return nil, 0, fmt.Errorf("[TopicGenerator] Unknown topic embedding provider %q", provider)
+}
+
+// openAIEmbedder calls the OpenAI embeddings API or anything that speaks it
+type openAIEmbedder struct {
+	name   string
+	client *openai.Client
+	model  string
+}
+
+func newOpenAIEmbedder(name string, clientConfig openai.ClientConfig, model string) *openAIEmbedder {
+	return &openAIEmbedder{
+		name:   name,
+		client: openai.NewClientWithConfig(clientConfig),
+		model:  model,
+	}
+}
+
+func (o *openAIEmbedder) Name() string {
+	return o.name
+}
+
+func (o *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for i := 0; i < len(texts); i += EMBEDDING_BATCH_SIZE {
+		end := min(i+EMBEDDING_BATCH_SIZE, len(texts))
+
+		resp, err := o.client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+			Input: texts[i:end],
+			Model: openai.EmbeddingModel(o.model),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Data) != end-i {
+			return nil, fmt.Errorf("[TopicGenerator] Expected %d embeddings, got %d", end-i, len(resp.Data))
+		}
+
+		batch := make([][]float32, end-i)
+		for _, embedding := range resp.Data {
+			if embedding.Index < 0 || embedding.Index >= len(batch) {
+				return nil, fmt.Errorf("[TopicGenerator] Embedding index %d out of range", embedding.Index)
+			}
+			batch[embedding.Index] = embedding.Embedding
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// Words too common in headlines to say anything about the story
+var embeddingStopwords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {},
+	"for": {}, "from": {}, "has": {}, "have": {}, "in": {}, "is": {}, "it": {},
+	"its": {}, "of": {}, "on": {}, "or": {}, "over": {}, "says": {}, "that": {},
+	"the": {}, "to": {}, "was": {}, "will": {}, "with": {},
+}
+
+// LocalEmbedder is an offline stand-in for an embedding model. Words, pairs of
+// adjacent words and character trigrams are hashed into a fixed size vector,
+// so it only recognizes stories told with the same vocabulary. It is meant for
+// local development and is only used when asked for explicitly.
+type LocalEmbedder struct{}
+
+func NewLocalEmbedder() *LocalEmbedder {
+	return &LocalEmbedder{}
+}
+
+func (l *LocalEmbedder) Name() string {
+	return EMBEDDING_PROVIDER_LOCAL
+}
+
+func (l *LocalEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, localEmbedding(text))
+	}
+	return vectors, nil
+}
+
+// localEmbedding hashes the features of a text into a unit vector, the sign
+// of each feature comes from the hash too so collisions cancel out on average
+func localEmbedding(text string) []float32 {
+	vector := make([]float32, LOCAL_EMBEDDING_DIMENSIONS)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		if sum&(1<<63) != 0 {
+			weight = -weight
+		}
+		vector[sum%LOCAL_EMBEDDING_DIMENSIONS] += weight
+	}
+
+	var words []string
+	for _, word := range fingerprint.Normalize(text) {
+		if _, stop := embeddingStopwords[word]; !stop {
+			words = append(words, word)
+		}
+	}
+
+	for i, word := range words {
+		add(word, 1)
+		if i > 0 {
+			add(words[i-1]+" "+word, 0.5)
+		}
+		// trigrams let plurals and tenses of a word still count for something
+		runes := []rune(" " + word + " ")
+		for j := 0; j+3 <= len(runes); j++ {
+			add("#"+string(runes[j:j+3]), 0.25)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
+
+// cosineSimilarity of two vectors, zero when either is empty or they differ in size
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
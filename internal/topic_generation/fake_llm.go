@@ -0,0 +1,90 @@
+package topicgeneration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
+)
+
+// FakeLLM answers without a model so topic generation can run offline. The
+// same headlines always produce the same topics: the topic is the headline
+// without its publisher suffix and the category is the first one whose name or
+// subcategories appear in the title, or the first category in the taxonomy.
+type FakeLLM struct{}
+
+func NewFakeLLM() *FakeLLM {
+	return &FakeLLM{}
+}
+
+func (f *FakeLLM) Name() string {
+	return LLM_PROVIDER_FAKE
+}
+
+func (f *FakeLLM) Complete(ctx context.Context, messages []ChatMessage, _ *ResponseSchema) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	response := models.OpenAITopicResponse{Topics: []models.Topic{}}
+	for _, message := range messages {
+		if message.Role != ROLE_USER {
+			continue
+		}
+
+		var headline models.NewsAPIHeadline
+		if err := json.Unmarshal([]byte(message.Content), &headline); err != nil || headline.Title == "" {
+			continue
+		}
+
+		response.Topics = append(response.Topics, models.Topic{
+			Title:    headline.Title,
+			Topic:    fakeTopic(headline.Title),
+			Category: fakeCategory(headline.Title),
+			URL:      headline.URL,
+		})
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// fakeTopic drops the " - Publisher" suffix NewsAPI appends to titles
+func fakeTopic(title string) string {
+	if i := strings.LastIndex(title, " - "); i > 0 {
+		title = title[:i]
+	}
+	return strings.TrimSpace(title)
+}
+
+// fakeCategory picks the first category mentioned by the title
+func fakeCategory(title string) string {
+	categories := taxonomy.Get().Categories
+	if len(categories) == 0 {
+		return ""
+	}
+
+	words := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		words[word] = struct{}{}
+	}
+
+	for _, category := range categories {
+		hints := append([]string{category.Name}, category.Subcategories...)
+		for _, hint := range hints {
+			for _, word := range strings.Fields(strings.ToLower(hint)) {
+				if _, ok := words[strings.Trim(word, "&,")]; ok {
+					return category.Name
+				}
+			}
+		}
+	}
+	return categories[0].Name
+}
@@ -0,0 +1,192 @@
+package topicgeneration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Supported topic LLM providers, selected with TOPIC_LLM_PROVIDER
+const (
+	LLM_PROVIDER_OPENAI            = "openai"
+	LLM_PROVIDER_OPENAI_COMPATIBLE = "openai-compatible" // Ollama, llama.cpp and friends
+	LLM_PROVIDER_FAKE              = "fake"
+)
+
+const (
+	DEFAULT_TOPIC_LLM_MODEL    = openai.GPT4oMini // the oldest model with structured output
+	DEFAULT_COMPATIBLE_BASEURL = "http://localhost:11434/v1"
+)
+
+// Chat roles
+const (
+	ROLE_SYSTEM    = "system"
+	ROLE_USER      = "user"
+	ROLE_ASSISTANT = "assistant"
+)
+
+// ChatMessage is a single message of the conversation sent to the model
+type ChatMessage struct {
+	Role    string
+	Content string
+}
+
+// ResponseSchema is the JSON schema the response has to follow
+type ResponseSchema struct {
+	Name   string
+	Schema json.RawMessage
+}
+
+// TopicLLM turns a conversation of headlines into the raw JSON topic response,
+// providers that support structured output are held to the schema
+type TopicLLM interface {
+	Name() string
+	Complete(ctx context.Context, messages []ChatMessage, schema *ResponseSchema) (string, error)
+}
+
+// TopicLLMConfig is read from env by NewTopicLLM
+type TopicLLMConfig struct {
+	Provider    string
+	Model       string
+	Temperature *float32 // nil leaves it to the server default
+	BaseURL     string
+	APIKey      string
+	// JSONMode asks the server for a JSON object response and StructuredOutput
+	// for one that follows the schema, some local servers support neither
+	JSONMode         bool
+	StructuredOutput bool
+}
+
+// LoadTopicLLMConfig reads the topic LLM settings from env
+func LoadTopicLLMConfig() TopicLLMConfig {
+	cfg := TopicLLMConfig{
+		Provider:         os.Getenv("TOPIC_LLM_PROVIDER"),
+		Model:            os.Getenv("TOPIC_LLM_MODEL"),
+		BaseURL:          os.Getenv("TOPIC_LLM_BASE_URL"),
+		APIKey:           os.Getenv("TOPIC_LLM_API_KEY"),
+		JSONMode:         os.Getenv("TOPIC_LLM_JSON_MODE") != "false",
+		StructuredOutput: os.Getenv("TOPIC_LLM_STRUCTURED_OUTPUT") != "false",
+	}
+
+	if cfg.Provider == "" {
+		cfg.Provider = LLM_PROVIDER_OPENAI
+	}
+	if cfg.Model == "" {
+		cfg.Model = DEFAULT_TOPIC_LLM_MODEL
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if temperature, err := strconv.ParseFloat(os.Getenv("TOPIC_LLM_TEMPERATURE"), 32); err == nil {
+		t := float32(temperature)
+		cfg.Temperature = &t
+	}
+	return cfg
+}
+
+// NewTopicLLM builds the provider configured in env
+func NewTopicLLM() (TopicLLM, error) {
+	cfg := LoadTopicLLMConfig()
+
+	slog.Info("[TopicGenerator] Using topic LLM",
+		slog.String("provider", cfg.Provider),
+		slog.String("model", cfg.Model))
+
+	switch cfg.Provider {
+	case LLM_PROVIDER_OPENAI:
+		if cfg.APIKey == "" {
+			return nil, errors.New("[TopicGenerator] Missing OPENAI_API_KEY for the openai provider")
+		}
+		clientConfig := openai.DefaultConfig(cfg.APIKey)
+		if cfg.BaseURL != "" {
+			clientConfig.BaseURL = cfg.BaseURL
+		}
+		return newOpenAILLM(LLM_PROVIDER_OPENAI, clientConfig, cfg), nil
+
+	case LLM_PROVIDER_OPENAI_COMPATIBLE:
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = DEFAULT_COMPATIBLE_BASEURL
+		}
+		// local servers accept any key but the client insists on sending one
+		clientConfig := openai.DefaultConfig(cfg.APIKey)
+		clientConfig.BaseURL = cfg.BaseURL
+		return newOpenAILLM(LLM_PROVIDER_OPENAI_COMPATIBLE, clientConfig, cfg), nil
+
+	case LLM_PROVIDER_FAKE:
+		return NewFakeLLM(), nil
+	}
+
+	return nil, fmt.Errorf("[TopicGenerator] Unknown topic LLM provider %q", cfg.Provider)
+}
+
+// openAILLM talks to the OpenAI chat completions API or anything that speaks it
+type openAILLM struct {
+	name   string
+	client *openai.Client
+	cfg    TopicLLMConfig
+}
+
+func newOpenAILLM(name string, clientConfig openai.ClientConfig, cfg TopicLLMConfig) *openAILLM {
+	return &openAILLM{
+		name:   name,
+		client: openai.NewClientWithConfig(clientConfig),
+		cfg:    cfg,
+	}
+}
+
+func (o *openAILLM) Name() string {
+	return o.name
+}
+
+func (o *openAILLM) Complete(ctx context.Context, messages []ChatMessage, schema *ResponseSchema) (string, error) {
+	request := openai.ChatCompletionRequest{
+		Model:    o.cfg.Model,
+		Messages: make([]openai.ChatCompletionMessage, 0, len(messages)),
+	}
+	if o.cfg.Temperature != nil {
+		request.Temperature = *o.cfg.Temperature
+		// a zero temperature is dropped from the request, the smallest non
+		// zero value is how the client sends an explicit 0
+		if request.Temperature == 0 {
+			request.Temperature = math.SmallestNonzeroFloat32
+		}
+	}
+	switch {
+	case schema != nil && o.cfg.StructuredOutput:
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   schema.Name,
+				Schema: schema.Schema,
+				Strict: true,
+			},
+		}
+	case o.cfg.JSONMode:
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	for _, message := range messages {
+		request.Messages = append(request.Messages, openai.ChatCompletionMessage{
+			Role:    message.Role,
+			Content: message.Content,
+		})
+	}
+
+	resp, err := o.client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("[TopicGenerator] Model returned no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,156 @@
+package topicgeneration
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/spacesedan/sentiflow/internal/db"
+	"github.com/spacesedan/sentiflow/internal/models"
+)
+
+// topicDeduper merges topics that cover the same story. Outlets word their
+// headlines differently so URLs don't catch these, topics closer than the
+// threshold in embedding space are merged into the first one seen.
+type topicDeduper struct {
+	embedder  TopicEmbedder
+	threshold float64
+
+	// topics already stored and their embeddings, embedded on first use
+	stored        []models.Topic
+	storedVectors [][]float32
+	embedded      bool
+}
+
+func newTopicDeduper(embedder TopicEmbedder, threshold float64, stored []models.Topic) *topicDeduper {
+	return &topicDeduper{
+		embedder:  embedder,
+		threshold: threshold,
+		stored:    slices.Clone(stored),
+	}
+}
+
+// Dedupe clusters new topics against the stored topics and each other. Topics
+// that match a stored topic have their URLs added to it, the rest are merged
+// into canonical topics that keep the URL of every headline merged into them.
+// The stored topics that were merged into are returned too, with only the URLs
+// of the new headlines as their source URLs. Topics are returned untouched
+// when they can't be embedded.
+func (d *topicDeduper) Dedupe(ctx context.Context, topics []models.Topic) ([]models.Topic, []models.Topic) {
+	if d == nil || d.embedder == nil || len(topics) == 0 {
+		return topics, nil
+	}
+
+	if !d.embedded {
+		vectors, err := d.embedder.Embed(ctx, topicTexts(d.stored))
+		if err != nil {
+			slog.Warn("[TopicGenerator] Failed to embed stored topics, skipping semantic dedupe",
+				slog.String("provider", d.embedder.Name()),
+				slog.String("error", err.Error()))
+			return topics, nil
+		}
+		d.storedVectors = vectors
+		d.embedded = true
+	}
+
+	vectors, err := d.embedder.Embed(ctx, topicTexts(topics))
+	if err != nil {
+		slog.Warn("[TopicGenerator] Failed to embed new topics, skipping semantic dedupe",
+			slog.String("provider", d.embedder.Name()),
+			slog.String("error", err.Error()))
+		return topics, nil
+	}
+
+	var canonical []models.Topic
+	var canonicalVectors [][]float32
+	mergedIntoStored := make(map[int][]string)
+	intoStored, intoNew := 0, 0
+
+	for i, topic := range topics {
+		if match, ok := closestVector(vectors[i], d.storedVectors, d.threshold); ok {
+			mergedIntoStored[match] = append(mergedIntoStored[match], sourceURLs(topic)...)
+			intoStored++
+			continue
+		}
+
+		if match, ok := closestVector(vectors[i], canonicalVectors, d.threshold); ok {
+			canonical[match].SourceURLs = mergeURLs(canonical[match].SourceURLs, sourceURLs(topic))
+			intoNew++
+			continue
+		}
+
+		topic.SourceURLs = sourceURLs(topic)
+		canonical = append(canonical, topic)
+		canonicalVectors = append(canonicalVectors, vectors[i])
+	}
+
+	mergedInto := make([]models.Topic, 0, len(mergedIntoStored))
+	for index, urls := range mergedIntoStored {
+		stored := &d.stored[index]
+
+		merged := *stored
+		merged.SourceURLs = urls
+		mergedInto = append(mergedInto, merged)
+
+		urls = mergeURLs(sourceURLs(*stored), urls)
+		if err := db.AddTopicSourceURLs(ctx, stored.URL, urls); err != nil {
+			slog.Warn("[TopicGenerator] Failed to merge duplicates into stored topic",
+				slog.String("topic", stored.Topic),
+				slog.String("error", err.Error()))
+			continue
+		}
+		stored.SourceURLs = urls
+	}
+
+	// later batches are deduped against this one too
+	d.stored = append(d.stored, canonical...)
+	d.storedVectors = append(d.storedVectors, canonicalVectors...)
+
+	slog.Info("[TopicGenerator] Merged semantically duplicate topics",
+		slog.Int("starting", len(topics)),
+		slog.Int("merged_into_stored", intoStored),
+		slog.Int("merged_into_new", intoNew),
+		slog.Int("ending", len(canonical)))
+
+	return canonical, mergedInto
+}
+
+// closestVector returns the index of the most similar vector at or above the threshold
+func closestVector(vector []float32, candidates [][]float32, threshold float64) (int, bool) {
+	best, bestSimilarity := -1, threshold
+	for i, candidate := range candidates {
+		if similarity := cosineSimilarity(vector, candidate); similarity >= bestSimilarity {
+			best, bestSimilarity = i, similarity
+		}
+	}
+	return best, best >= 0
+}
+
+// topicTexts is what gets embedded for each topic, the query is the model's
+// condensed version of the headline without the outlet's wording around it
+func topicTexts(topics []models.Topic) []string {
+	texts := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		texts = append(texts, topic.Topic)
+	}
+	return texts
+}
+
+// sourceURLs of a topic, topics stored before merging have only their own URL
+func sourceURLs(topic models.Topic) []string {
+	if len(topic.SourceURLs) > 0 {
+		return topic.SourceURLs
+	}
+	return []string{topic.URL}
+}
+
+// mergeURLs appends the URLs not already in urls
+func mergeURLs(urls []string, more []string) []string {
+	merged := slices.Clone(urls)
+	for _, url := range more {
+		if !slices.Contains(merged, url) {
+			merged = append(merged, url)
+		}
+	}
+	return merged
+}
@@ -3,23 +3,33 @@ package topicgeneration
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/sashabaranov/go-openai"
-	"github.com/spacesedan/sentiflow/internal/clients"
 	"github.com/spacesedan/sentiflow/internal/db"
 	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/rawcontent"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
 	"github.com/spacesedan/sentiflow/internal/utils"
 )
 
 var headlineBuffer = utils.NewBatchBuffer[models.NewsAPIArticles]()
 
-// GenerateTopicsFromHeadlines processes new headlines in batches, dedupes results, and merges them
-func GenerateTopicsFromHeadlines(ctx context.Context, headlines []models.NewsAPIArticles) {
+// PublishArticles enables publishing the articles behind new topics to raw-content
+var PublishArticles bool
+
+// GenerateTopicsFromHeadlines processes new headlines in batches with the given
+// model, dedupes results by URL and by meaning, and merges them. A nil embedder
+// leaves out the semantic dedupe.
+func GenerateTopicsFromHeadlines(ctx context.Context, llm TopicLLM, embedder TopicEmbedder, threshold float64, headlines []models.NewsAPIArticles) {
 	slog.Info("[TopicGenerator] Starting topic generation")
 
+	stats := newGenerationStats()
+	defer stats.Log()
+
 	var err error
 	var storedTopics []models.Topic
 
@@ -28,12 +38,13 @@ func GenerateTopicsFromHeadlines(ctx context.Context, headlines []models.NewsAPI
 		slog.Error("[TopicGenerator] Failed to fetch stored topics", slog.String("error", err.Error()))
 		storedTopics = []models.Topic{} // Fallback to empty
 	}
+	deduper := newTopicDeduper(embedder, threshold, storedTopics)
 
 	for _, headline := range headlines {
 		select {
 		case <-ctx.Done():
 			slog.Warn("[TopicGenerator] context canceled, flushing remaining buffer")
-			if err := processHeadlineBatch(ctx, storedTopics); err != nil {
+			if err := processHeadlineBatch(ctx, llm, storedTopics, deduper, stats); err != nil {
 				slog.Error("[TopicGenerator] context canceled; flushing remaining buffer",
 					slog.String("error", err.Error()))
 			}
@@ -41,7 +52,7 @@ func GenerateTopicsFromHeadlines(ctx context.Context, headlines []models.NewsAPI
 		default:
 			headlineBuffer.Add(headline)
 			if headlineBuffer.Size() >= 100 {
-				if err := processHeadlineBatch(ctx, storedTopics); err != nil {
+				if err := processHeadlineBatch(ctx, llm, storedTopics, deduper, stats); err != nil {
 					slog.Error("[TopicGenerator] Error flushing buffer",
 						slog.String("error", err.Error()))
 				}
@@ -50,69 +61,228 @@ func GenerateTopicsFromHeadlines(ctx context.Context, headlines []models.NewsAPI
 	}
 
 	if headlineBuffer.Size() > 0 {
-		if err := processHeadlineBatch(ctx, storedTopics); err != nil {
+		if err := processHeadlineBatch(ctx, llm, storedTopics, deduper, stats); err != nil {
 			slog.Error("[TopicGenerator] Error processing final batch",
 				slog.String("error", err.Error()))
 		}
 	}
 }
 
-func processHeadlineBatch(ctx context.Context, storedTopics []models.Topic) error {
+func processHeadlineBatch(ctx context.Context, llm TopicLLM, storedTopics []models.Topic, deduper *topicDeduper, stats *GenerationStats) error {
 	batch := headlineBuffer.GetAndClear()
 	if len(batch) == 0 {
 		return nil
 	}
 
-	var completionErr error
-	var resp openai.ChatCompletionResponse
+	generatedTopics, err := generateTopics(ctx, llm, batch, stats)
+	if err != nil {
+		return err
+	}
+
+	uniqueTopics := removeLocalDuplicates(generatedTopics)
+	attachOrigins(uniqueTopics, batch)
+	filteredTopics, mergedInto := deduper.Dedupe(ctx, filterAgainstStored(uniqueTopics, storedTopics))
+
+	if err := db.StoreBatchedTopics(ctx, filteredTopics); err != nil {
+		slog.Error("Failed to store generated topics in db",
+			slog.String("error", err.Error()))
+		return err
+	}
+
+	// articles merged into a stored topic are published under it, so every
+	// outlet's take on the story is there to compare
+	if PublishArticles {
+		publishArticles(ctx, batch, slices.Concat(filteredTopics, mergedInto))
+	}
 
+	return nil
+}
+
+// generateTopics asks the model for a topic per headline and validates the
+// response. Invalid topics are sent back with what was wrong with them for up
+// to TOPIC_LLM_MAX_REPAIRS rounds, whatever is still invalid after that is
+// rejected.
+func generateTopics(ctx context.Context, llm TopicLLM, batch []models.NewsAPIArticles, stats *GenerationStats) ([]models.Topic, error) {
+	headlines := make(map[string]models.NewsAPIArticles, len(batch))
+	for _, headline := range batch {
+		headlines[headline.URL] = headline
+	}
+
+	schema := topicResponseSchema()
 	messages := buildChatMessage(batch)
+	maxRepairs := maxRepairRounds()
+
+	var accepted []models.Topic
+	acceptedURLs := make(map[string]struct{})
+	// the latest problem of every headline without a topic, keyed by its URL
+	unresolved := make(map[string]topicProblem)
+
+	for round := 0; round <= maxRepairs; round++ {
+		if round > 0 {
+			stats.recordRepairRound()
+		}
+
+		completion, err := completeWithRetry(ctx, llm, messages, schema)
+		if err != nil {
+			if round == 0 {
+				return nil, err
+			}
+			// keep what the earlier rounds produced
+			break
+		}
+
+		var problems []topicProblem
+		response, err := parseTopicResponse(completion)
+		if err != nil {
+			problems = append(problems, topicProblem{reason: REJECT_INVALID_RESPONSE, detail: err.Error()})
+			for _, headline := range batch {
+				if _, exists := acceptedURLs[headline.URL]; !exists {
+					unresolved[headline.URL] = topicProblem{key: headline.URL, reason: REJECT_INVALID_RESPONSE, detail: err.Error()}
+				}
+			}
+		} else {
+			// only the headlines without a valid topic yet are taken from a repair
+			var valid []models.Topic
+			valid, problems = validateTopics(response.Topics, headlines)
+			added := 0
+			for _, topic := range valid {
+				if _, exists := acceptedURLs[topic.URL]; exists {
+					continue
+				}
+				acceptedURLs[topic.URL] = struct{}{}
+				delete(unresolved, topic.URL)
+				accepted = append(accepted, topic)
+				added++
+			}
+			stats.recordAccepted(added, round > 0)
+			problems = append(problems, missingTopicProblems(batch, acceptedURLs, problems)...)
+		}
+
+		// problems that can't be traced to a headline are still sent back so
+		// the model sees them, the headline they were meant for is missing
+		var pending []topicProblem
+		for _, problem := range problems {
+			if _, exists := acceptedURLs[problem.key]; exists {
+				continue
+			}
+			if _, sent := headlines[problem.key]; sent {
+				unresolved[problem.key] = problem
+			}
+			pending = append(pending, problem)
+		}
+		if len(unresolved) == 0 {
+			break
+		}
+
+		slog.Warn("[TopicGenerator] Generated topics failed validation",
+			slog.String("provider", llm.Name()),
+			slog.Int("invalid", len(unresolved)),
+			slog.Int("round", round))
+
+		messages = append(messages,
+			ChatMessage{Role: ROLE_ASSISTANT, Content: completion},
+			buildRepairMessage(pending))
+	}
+
+	for _, headline := range batch {
+		problem, ok := unresolved[headline.URL]
+		if !ok {
+			continue
+		}
+		delete(unresolved, headline.URL)
+		stats.recordRejection(problem.reason)
+		slog.Warn("[TopicGenerator] Rejected generated topic",
+			slog.String("reason", problem.reason),
+			slog.String("detail", problem.detail))
+	}
+
+	return accepted, nil
+}
+
+// completeWithRetry retries failed completions, a response that fails
+// validation is handled by the repair rounds instead
+func completeWithRetry(ctx context.Context, llm TopicLLM, messages []ChatMessage, schema *ResponseSchema) (string, error) {
+	var completionErr error
+	var completion string
 
 	for i := 0; i < 3; i++ {
 		start := time.Now()
-		resp, completionErr = clients.GetOpenAIClient().Client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model:    openai.GPT3Dot5Turbo1106,
-			Messages: messages,
-			ResponseFormat: &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-			},
-		})
+		completion, completionErr = llm.Complete(ctx, messages, schema)
 		if completionErr == nil {
-			break
+			return completion, nil
 		}
-		slog.Warn("Failed to get a response from OpenAI, retrying...",
+		slog.Warn("Failed to get a response from the topic LLM, retrying...",
+			slog.String("provider", llm.Name()),
 			slog.String("error", completionErr.Error()),
 			slog.Int("attempt", i+1),
 			slog.Duration("elapsed", time.Since(start)))
 	}
-	if completionErr != nil {
-		slog.Warn("failed to get a response from OpenAI after 3 tries",
-			slog.String("error", completionErr.Error()))
-		return completionErr
+
+	slog.Warn("failed to get a response from the topic LLM after 3 tries",
+		slog.String("provider", llm.Name()),
+		slog.String("error", completionErr.Error()))
+	return "", completionErr
+}
+
+// attachOrigins records the country, language or query that produced the
+// headline behind each topic
+func attachOrigins(topics []models.Topic, batch []models.NewsAPIArticles) {
+	origins := make(map[string]models.NewsAPIOrigin, len(batch))
+	for _, article := range batch {
+		origins[article.URL] = article.Origin
 	}
 
-	cleanedResponse := cleanOpenAIResponse(resp.Choices[0].Message.Content)
+	for i := range topics {
+		origin, ok := origins[topics[i].URL]
+		if !ok {
+			continue
+		}
+		topics[i].Country = origin.Country
+		topics[i].Language = origin.Language
+		topics[i].Query = origin.Query
+	}
+}
 
-	var generatedTopics *models.OpenAITopicResponse
-	if err := json.Unmarshal([]byte(cleanedResponse), &generatedTopics); err != nil {
-		slog.Error("Failed to unmarshal generated topics",
-			slog.String("error", err.Error()))
-		return err
+// publishArticles sends the articles behind the new topics to raw-content so
+// the press framing of a topic can be compared with the discussion around it
+func publishArticles(ctx context.Context, batch []models.NewsAPIArticles, topics []models.Topic) {
+	articlesByURL := make(map[string]models.NewsAPIArticles, len(batch))
+	for _, article := range batch {
+		articlesByURL[article.URL] = article
 	}
 
-	uniqueTopics := removeLocalDuplicates(generatedTopics.Topics)
-	filteredTopics := filterAgainstStored(uniqueTopics, storedTopics)
+	published := 0
+	for _, topic := range topics {
+		// every outlet's article behind a merged topic is published under it
+		for _, url := range sourceURLs(topic) {
+			article, ok := articlesByURL[url]
+			if !ok {
+				continue
+			}
+
+			content := rawcontent.NewsArticleToRaw(topic.Topic, article)
+			if content.Text == "" {
+				continue
+			}
 
-	if err := db.StoreBatchedTopics(ctx, filteredTopics); err != nil {
-		slog.Error("Failed to store generated topics in db",
-			slog.String("error", err.Error()))
-		return err
+			if err := rawcontent.Publish(ctx, content); err != nil {
+				if !errors.Is(err, rawcontent.ErrDuplicateContent) {
+					slog.Warn("[TopicGenerator] Failed to publish article",
+						slog.String("url", article.URL),
+						slog.String("error", err.Error()))
+				}
+				continue
+			}
+			published++
+		}
 	}
 
-	return nil
+	slog.Info("[TopicGenerator] Published articles for new topics",
+		slog.Int("published", published),
+		slog.Int("topics", len(topics)))
 }
 
-func buildChatMessage(headlines []models.NewsAPIArticles) []openai.ChatCompletionMessage {
+func buildChatMessage(headlines []models.NewsAPIArticles) []ChatMessage {
 	systemMessage := `
 You will receive several news headlines as JSON objects.
 Respond ONLY with a valid JSON object, without any additional commentary.
@@ -121,15 +291,7 @@ Each topic must have:
 - "title": The original title as it was sent.
 - "topic": Concise, clear, and easily searchable (queryable).
 - "category": One of the following predefined categories:
-  - Technology
-  - Business & Finance
-  - Politics & World Affairs
-  - Entertainment & Pop Culture
-  - Health & Science
-  - Sports
-  - Lifestyle & Society
-  - Memes & Internet Trends
-  - Crime & Law
+` + categoryPromptList() + categoryPromptHints() + `
 - "url": Original URL from the headline.
 
 JSON response structure:
@@ -146,15 +308,15 @@ JSON response structure:
 }
 `
 
-	messages := []openai.ChatCompletionMessage{
+	messages := []ChatMessage{
 		{
-			Role:    openai.ChatMessageRoleSystem,
+			Role:    ROLE_SYSTEM,
 			Content: systemMessage,
 		},
 	}
 
 	for _, headline := range headlines {
-		bytes, err := json.Marshal(headline)
+		bytes, err := json.Marshal(models.NewsAPIHeadline{Title: headline.Title, URL: headline.URL})
 		if err != nil {
 			slog.Warn("Failed to marshal headline",
 				slog.String("headline", headline.Title),
@@ -162,8 +324,8 @@ JSON response structure:
 			continue
 		}
 
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
+		messages = append(messages, ChatMessage{
+			Role:    ROLE_USER,
 			Content: string(bytes),
 		})
 	}
@@ -171,30 +333,29 @@ JSON response structure:
 	return messages
 }
 
-func cleanOpenAIResponse(response string) string {
-	response = strings.TrimSpace(response)
-
-	// Find the first '{' and the last '}' to extract only JSON.
-	startIdx := strings.Index(response, "{")
-	endIdx := strings.LastIndex(response, "}")
-
-	if startIdx == -1 || endIdx == -1 || endIdx <= startIdx {
-		slog.Error("Could not extract valid JSON from OpenAI response", slog.String("raw_response", response))
-		return "" // or handle the error appropriately
+// categoryPromptList renders the taxonomy as the category list in the system
+// prompt, only the names are listed so the model doesn't answer with a subcategory
+func categoryPromptList() string {
+	names := taxonomy.Get().CategoryNames()
+	for i, name := range names {
+		names[i] = "  - " + name
 	}
+	return strings.Join(names, "\n")
+}
 
-	response = response[startIdx : endIdx+1]
-
-	// Remove Markdown code block if present.
-	response = strings.TrimPrefix(response, "```json")
-	response = strings.TrimSuffix(response, "```")
-
-	// Standardize quotes
-	response = strings.ReplaceAll(response, "\u0022", `"`)
-	response = strings.ReplaceAll(response, "\u201C", `"`)
-	response = strings.ReplaceAll(response, "\u201D", `"`)
-
-	return strings.TrimSpace(response)
+// categoryPromptHints renders the subcategories as a separate line of hints
+// for picking a category, empty when the taxonomy has none
+func categoryPromptHints() string {
+	var hints []string
+	for _, category := range taxonomy.Get().Categories {
+		if len(category.Subcategories) > 0 {
+			hints = append(hints, category.Name+" covers "+strings.Join(category.Subcategories, ", "))
+		}
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return "\n  Hints for picking a category, these are NOT valid category values: " + strings.Join(hints, "; ") + "."
 }
 
 // removeLocalDuplicates ensures the newly generated batch from OpenAI
@@ -215,12 +376,16 @@ func removeLocalDuplicates(topics []models.Topic) []models.Topic {
 	return unique
 }
 
-// filterAgainstStored removes any topics whose URL is already in storedTopics
+// filterAgainstStored removes any topics whose URL is already in storedTopics,
+// including the URLs merged into a stored topic
 func filterAgainstStored(newTopics []models.Topic, storedTopics []models.Topic) []models.Topic {
 	slog.Info("[TopicGenerator] Removing topics that have been previously stored", slog.Int("starting", len(newTopics)))
 	storedSet := make(map[string]struct{}, len(storedTopics))
 	for _, st := range storedTopics {
 		storedSet[st.URL] = struct{}{}
+		for _, url := range st.SourceURLs {
+			storedSet[url] = struct{}{}
+		}
 	}
 
 	var final []models.Topic
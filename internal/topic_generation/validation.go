@@ -0,0 +1,243 @@
+package topicgeneration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spacesedan/sentiflow/internal/models"
+	"github.com/spacesedan/sentiflow/internal/taxonomy"
+)
+
+// Reasons a generated topic is rejected, these are the keys rejections are
+// reported under
+const (
+	REJECT_INVALID_RESPONSE = "invalid_response"
+	REJECT_UNKNOWN_CATEGORY = "unknown_category"
+	REJECT_EMPTY_TOPIC      = "empty_topic"
+	REJECT_UNKNOWN_URL      = "unknown_url"
+	REJECT_MISSING_TOPIC    = "missing_topic"
+)
+
+// DEFAULT_MAX_REPAIR_ROUNDS is how many times invalid topics are sent back to
+// the model for correction, set with TOPIC_LLM_MAX_REPAIRS
+const DEFAULT_MAX_REPAIR_ROUNDS = 1
+
+// topicProblem is a generated topic that failed validation, key is the URL of
+// the headline it was generated for so a repaired topic resolves it. Topics
+// that can't be traced back to a headline have an empty key.
+type topicProblem struct {
+	key    string
+	reason string
+	detail string
+}
+
+// GenerationStats counts what happened to the topics the model generated
+type GenerationStats struct {
+	mu           sync.Mutex
+	accepted     int64
+	repaired     int64
+	repairRounds int64
+	rejections   map[string]int64
+}
+
+func newGenerationStats() *GenerationStats {
+	return &GenerationStats{rejections: make(map[string]int64)}
+}
+
+func (gs *GenerationStats) recordAccepted(topics int, repaired bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.accepted += int64(topics)
+	if repaired {
+		gs.repaired += int64(topics)
+	}
+}
+
+func (gs *GenerationStats) recordRepairRound() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.repairRounds++
+}
+
+func (gs *GenerationStats) recordRejection(reason string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.rejections[reason]++
+}
+
+func (gs *GenerationStats) Log() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	slog.Info("[TopicGenerator] Topic validation stats",
+		slog.Int64("accepted", gs.accepted),
+		slog.Int64("repaired", gs.repaired),
+		slog.Int64("repair_rounds", gs.repairRounds))
+
+	reasons := make([]string, 0, len(gs.rejections))
+	for reason := range gs.rejections {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	attrs := make([]any, 0, len(reasons))
+	for _, reason := range reasons {
+		attrs = append(attrs, slog.Int64(reason, gs.rejections[reason]))
+	}
+	slog.Info("[TopicGenerator] Topic validation rejections", attrs...)
+}
+
+// topicResponseSchema is the JSON schema of the topic response, categories are
+// limited to the taxonomy
+func topicResponseSchema() *ResponseSchema {
+	topic := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"title":    map[string]any{"type": "string"},
+			"topic":    map[string]any{"type": "string"},
+			"category": map[string]any{"type": "string", "enum": taxonomy.Get().CategoryNames()},
+			"url":      map[string]any{"type": "string"},
+		},
+		"required":             []string{"title", "topic", "category", "url"},
+		"additionalProperties": false,
+	}
+
+	schema, err := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"topics": map[string]any{"type": "array", "items": topic},
+		},
+		"required":             []string{"topics"},
+		"additionalProperties": false,
+	})
+	if err != nil {
+		return nil
+	}
+	return &ResponseSchema{Name: "topics", Schema: schema}
+}
+
+// parseTopicResponse decodes the model response, the only leniency is a
+// Markdown code fence around the JSON since local models add one out of habit
+func parseTopicResponse(response string) (*models.OpenAITopicResponse, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		response = strings.TrimPrefix(response, "```json")
+		response = strings.TrimPrefix(response, "```")
+		response = strings.TrimSuffix(response, "```")
+	}
+
+	var parsed models.OpenAITopicResponse
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	if parsed.Topics == nil {
+		return nil, errors.New(`response has no "topics" array`)
+	}
+	return &parsed, nil
+}
+
+// validateTopics keeps the topics that have a category from the taxonomy, a
+// non-empty query and the URL of one of the headlines they were generated from.
+// Accepted topics get the original title of their headline.
+func validateTopics(topics []models.Topic, headlines map[string]models.NewsAPIArticles) ([]models.Topic, []topicProblem) {
+	titles := make(map[string]string, len(headlines))
+	for url, headline := range headlines {
+		titles[headline.Title] = url
+	}
+
+	var valid []models.Topic
+	var problems []topicProblem
+	for _, topic := range topics {
+		headline, known := headlines[topic.URL]
+		if !known {
+			// the title points at the headline the model meant to answer for
+			key := ""
+			if url, ok := titles[topic.Title]; ok {
+				key = url
+			}
+			problems = append(problems, topicProblem{
+				key:    key,
+				reason: REJECT_UNKNOWN_URL,
+				detail: fmt.Sprintf("url %q is not the url of any headline that was sent", topic.URL),
+			})
+			continue
+		}
+
+		if _, ok := taxonomy.Get().Category(topic.Category); !ok {
+			problems = append(problems, topicProblem{
+				key:    topic.URL,
+				reason: REJECT_UNKNOWN_CATEGORY,
+				detail: fmt.Sprintf("url %q: category %q is not one of the predefined categories", topic.URL, topic.Category),
+			})
+			continue
+		}
+
+		topic.Topic = strings.TrimSpace(topic.Topic)
+		if topic.Topic == "" {
+			problems = append(problems, topicProblem{
+				key:    topic.URL,
+				reason: REJECT_EMPTY_TOPIC,
+				detail: fmt.Sprintf("url %q: topic is empty", topic.URL),
+			})
+			continue
+		}
+
+		topic.Title = headline.Title
+		valid = append(valid, topic)
+	}
+	return valid, problems
+}
+
+// missingTopicProblems reports the headlines the model left out of its response
+// that don't have a topic or a problem yet
+func missingTopicProblems(batch []models.NewsAPIArticles, accepted map[string]struct{}, problems []topicProblem) []topicProblem {
+	reported := make(map[string]struct{}, len(problems))
+	for _, problem := range problems {
+		reported[problem.key] = struct{}{}
+	}
+
+	var missing []topicProblem
+	for _, headline := range batch {
+		if _, ok := accepted[headline.URL]; ok {
+			continue
+		}
+		if _, ok := reported[headline.URL]; ok {
+			continue
+		}
+		missing = append(missing, topicProblem{
+			key:    headline.URL,
+			reason: REJECT_MISSING_TOPIC,
+			detail: fmt.Sprintf("url %q: no topic was generated for the headline %q", headline.URL, headline.Title),
+		})
+	}
+	return missing
+}
+
+// buildRepairMessage asks the model to correct the topics that failed validation
+func buildRepairMessage(problems []topicProblem) ChatMessage {
+	var b strings.Builder
+	b.WriteString("Some topics in your previous response were invalid:\n")
+	for _, problem := range problems {
+		b.WriteString("- " + problem.detail + "\n")
+	}
+	b.WriteString("\nRespond with the same JSON structure containing corrected topics for these headlines only. " +
+		"Use the url exactly as it was sent and a category from the predefined list.")
+
+	return ChatMessage{Role: ROLE_USER, Content: b.String()}
+}
+
+// maxRepairRounds reads TOPIC_LLM_MAX_REPAIRS, zero disables repairs
+func maxRepairRounds() int {
+	rounds, err := strconv.Atoi(os.Getenv("TOPIC_LLM_MAX_REPAIRS"))
+	if err != nil || rounds < 0 {
+		return DEFAULT_MAX_REPAIR_ROUNDS
+	}
+	return rounds
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlBlockTags = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/h[1-6]|/blockquote)\s*/?>`)
+	htmlTags      = regexp.MustCompile(`<[^>]*>`)
+	htmlSkipped   = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	spaceRuns     = regexp.MustCompile(`[ \t\f\v]+`)
+	newlineRuns   = regexp.MustCompile(`\n{3,}`)
+)
+
+// StripHTML turns an HTML fragment into plain text, block level tags become
+// line breaks and entities are decoded
+func StripHTML(s string) string {
+	s = htmlSkipped.ReplaceAllString(s, "")
+	s = htmlBlockTags.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	s = spaceRuns.ReplaceAllString(s, " ")
+	s = newlineRuns.ReplaceAllString(s, "\n\n")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
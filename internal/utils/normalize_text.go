@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"bytes"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// How URLs are treated when normalizing text, set with TEXT_URL_MODE
+const (
+	URL_MODE_STRIP = "strip" // URLs are removed
+	URL_MODE_TOKEN = "token" // URLs are replaced with URL_TOKEN
+)
+
+// URL_TOKEN stands in for URLs so the analyzer still knows a link was shared
+const URL_TOKEN = "URL"
+
+const markdownExtensions = blackfriday.NoIntraEmphasis | blackfriday.FencedCode |
+	blackfriday.Autolink | blackfriday.Strikethrough | blackfriday.SpaceHeadings |
+	blackfriday.Tables
+
+var (
+	zeroWidth     = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "", "\u00a0", " ")
+	bareURLs      = regexp.MustCompile(`https?://\S+|www\.\S+`)
+	inlineSpaces  = regexp.MustCompile(`[ \t]+`)
+	blankLineRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+// Sources whose text is written in Markdown, everything else is plain text
+// where an indented line or a leading > is just part of what was written
+var markdownSources = map[string]struct{}{
+	"reddit":     {},
+	"hackernews": {},
+}
+
+// NormalizeSourceText normalizes text the way its source writes it, Markdown
+// is only rendered for the sources that use it
+func NormalizeSourceText(source, text string) string {
+	if _, ok := markdownSources[source]; ok {
+		return NormalizeText(text)
+	}
+	return NormalizePlainText(text)
+}
+
+// NormalizePlainText cleans up plain text for analysis. Entities are decoded,
+// whitespace is collapsed and bare URLs are stripped or tokenized depending on
+// TEXT_URL_MODE.
+func NormalizePlainText(text string) string {
+	text = zeroWidth.Replace(html.UnescapeString(text))
+	return collapseWhitespace(replaceURLs(text, textURLMode()))
+}
+
+// NormalizeText renders Markdown to plain text for analysis. Entities are
+// decoded, quotes and code blocks are dropped since they are someone else's
+// words or not words at all, links keep their text and bare URLs are stripped
+// or tokenized depending on TEXT_URL_MODE.
+func NormalizeText(text string) string {
+	urlMode := textURLMode()
+
+	// Reddit escapes entities in selftext, quotes arrive as &gt; so entities
+	// are decoded before parsing for the Markdown to be recognized
+	text = zeroWidth.Replace(html.UnescapeString(text))
+
+	root := blackfriday.New(blackfriday.WithExtensions(markdownExtensions)).Parse([]byte(text))
+
+	var out bytes.Buffer
+	root.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		switch node.Type {
+		case blackfriday.BlockQuote, blackfriday.CodeBlock:
+			return blackfriday.SkipChildren
+
+		case blackfriday.Link:
+			if !entering {
+				return blackfriday.GoToNext
+			}
+			// autolinks and links labelled with their own URL carry no words
+			label := strings.TrimSpace(nodeText(node))
+			if label == "" || label == string(node.LinkData.Destination) || bareURLs.MatchString(label) {
+				if urlMode == URL_MODE_TOKEN {
+					out.WriteString(URL_TOKEN)
+				}
+				return blackfriday.SkipChildren
+			}
+
+		case blackfriday.Image:
+			if entering {
+				out.WriteString(nodeText(node))
+			}
+			return blackfriday.SkipChildren
+
+		case blackfriday.Text, blackfriday.Code:
+			out.Write(node.Literal)
+
+		case blackfriday.HTMLSpan, blackfriday.HTMLBlock:
+			out.WriteString(StripHTML(string(node.Literal)))
+
+		case blackfriday.Softbreak:
+			out.WriteString(" ")
+
+		case blackfriday.Hardbreak:
+			out.WriteString("\n")
+
+		case blackfriday.Paragraph, blackfriday.Heading, blackfriday.Item, blackfriday.TableRow:
+			if !entering {
+				out.WriteString("\n\n")
+			}
+
+		case blackfriday.TableCell:
+			if !entering {
+				out.WriteString(" ")
+			}
+		}
+		return blackfriday.GoToNext
+	})
+
+	return collapseWhitespace(replaceURLs(out.String(), urlMode))
+}
+
+func textURLMode() string {
+	if os.Getenv("TEXT_URL_MODE") == URL_MODE_TOKEN {
+		return URL_MODE_TOKEN
+	}
+	return URL_MODE_STRIP
+}
+
+// replaceURLs strips the bare URLs in text or replaces them with URL_TOKEN
+func replaceURLs(text string, urlMode string) string {
+	if urlMode == URL_MODE_TOKEN {
+		return bareURLs.ReplaceAllString(text, URL_TOKEN)
+	}
+	return bareURLs.ReplaceAllString(text, "")
+}
+
+// nodeText is the text inside a node, the label of a link or the alt of an image
+func nodeText(node *blackfriday.Node) string {
+	var text strings.Builder
+	node.Walk(func(child *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if entering && (child.Type == blackfriday.Text || child.Type == blackfriday.Code) {
+			text.Write(child.Literal)
+		}
+		return blackfriday.GoToNext
+	})
+	return text.String()
+}
+
+// collapseWhitespace squeezes runs of spaces and keeps at most one blank line
+// between paragraphs
+func collapseWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(inlineSpaces.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLineRuns.ReplaceAllString(text, "\n\n"))
+}
@@ -4,10 +4,14 @@ import (
 	"github.com/spacesedan/sentiflow/internal/models"
 )
 
+// RawToSentimentAnalysisInput builds the analysis input from raw content, the
+// text is normalized for the models according to its source and the raw text
+// is kept in OriginalText
 func RawToSentimentAnalysisInput(c models.RawContent) models.SentimentAnalysisInput {
 	return models.SentimentAnalysisInput{
 		RawContent:    c,
-		Text:          c.Text,
+		Text:          NormalizeSourceText(c.Source, c.Text),
 		WasSummarized: false,
+		OriginalText:  c.Text,
 	}
 }
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// paragraphs shorter than this are usually captions, bylines or buttons
+	MIN_PARAGRAPH_LENGTH = 40
+	// paragraphs where links make up more than this share of the text are navigation
+	MAX_LINK_DENSITY = 0.5
+	// below this the paragraph extraction is assumed to have missed the article
+	MIN_ARTICLE_LENGTH = 200
+)
+
+var (
+	pageTitle       = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	ogTitle         = regexp.MustCompile(`(?is)<meta[^>]+property=["']og:title["'][^>]+content=["']([^"']*)["']`)
+	ogTitleReverse  = regexp.MustCompile(`(?is)<meta[^>]+content=["']([^"']*)["'][^>]+property=["']og:title["']`)
+	pageBoilerplate = regexp.MustCompile(
+		`(?is)<(script|style|noscript|svg|nav|header|footer|aside|form|iframe|template)\b[^>]*>.*?</(script|style|noscript|svg|nav|header|footer|aside|form|iframe|template)>`)
+	pageComments   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	articleElement = regexp.MustCompile(`(?is)<article\b[^>]*>(.*?)</article>`)
+	mainElement    = regexp.MustCompile(`(?is)<main\b[^>]*>(.*?)</main>`)
+	bodyElement    = regexp.MustCompile(`(?is)<body\b[^>]*>(.*)</body>`)
+	paragraphs     = regexp.MustCompile(`(?is)<p\b[^>]*>(.*?)</p>`)
+	anchors        = regexp.MustCompile(`(?is)<a\b[^>]*>(.*?)</a>`)
+)
+
+// ExtractArticle pulls the title and main text out of an HTML page. It follows
+// the readability approach in a simplified form, boilerplate elements are
+// dropped, the content root is narrowed to <article> or <main> when present and
+// only paragraphs that look like prose are kept.
+func ExtractArticle(page string) (string, string) {
+	title := extractTitle(page)
+
+	page = pageComments.ReplaceAllString(page, "")
+	page = pageBoilerplate.ReplaceAllString(page, "")
+
+	root := page
+	if m := articleElement.FindAllStringSubmatch(page, -1); len(m) > 0 {
+		// pages sometimes wrap teasers in <article> too, the longest one is the story
+		root = longestMatch(m)
+	} else if m := mainElement.FindStringSubmatch(page); m != nil {
+		root = m[1]
+	} else if m := bodyElement.FindStringSubmatch(page); m != nil {
+		root = m[1]
+	}
+
+	var kept []string
+	for _, m := range paragraphs.FindAllStringSubmatch(root, -1) {
+		text := normalizeParagraph(StripHTML(m[1]))
+		if len(text) < MIN_PARAGRAPH_LENGTH {
+			continue
+		}
+		if linkDensity(m[1], text) > MAX_LINK_DENSITY {
+			continue
+		}
+		kept = append(kept, text)
+	}
+
+	text := strings.Join(kept, "\n\n")
+	if len(text) < MIN_ARTICLE_LENGTH {
+		// no usable paragraphs, fall back to all the text in the content root
+		if fallback := StripHTML(root); len(fallback) > len(text) {
+			text = fallback
+		}
+	}
+
+	return title, strings.ToValidUTF8(text, "")
+}
+
+func extractTitle(page string) string {
+	for _, re := range []*regexp.Regexp{ogTitle, ogTitleReverse, pageTitle} {
+		if m := re.FindStringSubmatch(page); m != nil {
+			if title := normalizeParagraph(StripHTML(m[1])); title != "" {
+				return strings.ToValidUTF8(title, "")
+			}
+		}
+	}
+	return ""
+}
+
+func longestMatch(matches [][]string) string {
+	longest := ""
+	for _, m := range matches {
+		if len(m[1]) > len(longest) {
+			longest = m[1]
+		}
+	}
+	return longest
+}
+
+// normalizeParagraph collapses the line breaks inside a paragraph
+func normalizeParagraph(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// linkDensity is the share of a paragraphs text that sits inside links
+func linkDensity(fragment, text string) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+
+	linked := 0
+	for _, m := range anchors.FindAllStringSubmatch(fragment, -1) {
+		linked += len(normalizeParagraph(StripHTML(m[1])))
+	}
+	return float64(linked) / float64(len(text))
+}